@@ -0,0 +1,188 @@
+// Package changelog lists packages added, removed, renamed, and modified between two git revisions.
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/goccy/go-yaml"
+)
+
+// Changelog is changes of packages between two revisions.
+// Each list is sorted by package names.
+type Changelog struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Added    []string  `json:"added"`
+	Removed  []string  `json:"removed"`
+	Renamed  []*Rename `json:"renamed"`
+	Modified []string  `json:"modified"`
+}
+
+// Rename is a package renamed from From to To.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Empty returns true if no package is changed.
+func (c *Changelog) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Renamed) == 0 && len(c.Modified) == 0
+}
+
+// Generate compares pkgs/**/registry.yaml between the revisions from and to.
+// Files are read from git objects, so the working tree isn't changed.
+func Generate(ctx context.Context, repo git.Repo, from, to string) (*Changelog, error) {
+	oldPkgs, err := readPackages(ctx, repo, from)
+	if err != nil {
+		return nil, fmt.Errorf("read packages at %s: %w", from, err)
+	}
+	newPkgs, err := readPackages(ctx, repo, to)
+	if err != nil {
+		return nil, fmt.Errorf("read packages at %s: %w", to, err)
+	}
+	return compare(from, to, oldPkgs, newPkgs), nil
+}
+
+// readPackages reads pkgs/**/registry.yaml at the revision.
+// It returns a map from package names to packages.
+func readPackages(ctx context.Context, repo git.Repo, rev string) (map[string]*registry.PackageInfo, error) {
+	files, err := repo.LsTree(ctx, rev, "pkgs")
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+	files = slices.DeleteFunc(files, func(p string) bool {
+		return path.Base(p) != "registry.yaml"
+	})
+	contents, err := repo.CatFiles(ctx, rev, files)
+	if err != nil {
+		return nil, fmt.Errorf("read files: %w", err)
+	}
+	pkgs := make(map[string]*registry.PackageInfo, len(files))
+	for _, p := range files {
+		cfg := &registry.Config{}
+		if err := yaml.Unmarshal(contents[p], cfg); err != nil {
+			return nil, fmt.Errorf("parse %s as YAML: %w", p, err)
+		}
+		if len(cfg.PackageInfos) == 0 {
+			continue
+		}
+		pkgs[strings.TrimPrefix(path.Dir(p), "pkgs/")] = cfg.PackageInfos[0]
+	}
+	return pkgs, nil
+}
+
+// compare classifies packages.
+// A removed package is regarded as renamed if an added or existing package has it as a new alias,
+// or if an added package is same as it except for the name and either the owner or the repository name is kept.
+func compare(from, to string, oldPkgs, newPkgs map[string]*registry.PackageInfo) *Changelog {
+	c := &Changelog{
+		From:     from,
+		To:       to,
+		Added:    []string{},
+		Removed:  []string{},
+		Renamed:  []*Rename{},
+		Modified: []string{},
+	}
+	var added, removed []string
+	for name, pkg := range newPkgs {
+		oldPkg, ok := oldPkgs[name]
+		if !ok {
+			added = append(added, name)
+			continue
+		}
+		if !equal(oldPkg, pkg) {
+			c.Modified = append(c.Modified, name)
+		}
+	}
+	for name := range oldPkgs {
+		if _, ok := newPkgs[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	newNames := slices.Sorted(maps.Keys(newPkgs))
+	renamedTo := map[string]struct{}{}
+	for _, name := range removed {
+		newName, ok := findRename(name, oldPkgs, newPkgs, newNames, added, renamedTo)
+		if !ok {
+			c.Removed = append(c.Removed, name)
+			continue
+		}
+		renamedTo[newName] = struct{}{}
+		c.Renamed = append(c.Renamed, &Rename{From: name, To: newName})
+	}
+	for _, name := range added {
+		if _, ok := renamedTo[name]; !ok {
+			c.Added = append(c.Added, name)
+		}
+	}
+	slices.Sort(c.Modified)
+	return c
+}
+
+func findRename(name string, oldPkgs, newPkgs map[string]*registry.PackageInfo, newNames, added []string, renamedTo map[string]struct{}) (string, bool) {
+	// An alias is added
+	for _, newName := range newNames {
+		if hasAlias(newPkgs[newName], name) && (oldPkgs[newName] == nil || !hasAlias(oldPkgs[newName], name)) {
+			return newName, true
+		}
+	}
+	// The package is moved without aliases.
+	// Either the owner or the repository name must be kept, as transferred or renamed repositories,
+	// so that unrelated packages with the same settings aren't regarded as renamed.
+	oldPkg := oldPkgs[name]
+	for _, newName := range added {
+		if _, ok := renamedTo[newName]; ok {
+			continue
+		}
+		newPkg := newPkgs[newName]
+		if oldPkg.RepoOwner != newPkg.RepoOwner && oldPkg.RepoName != newPkg.RepoName {
+			continue
+		}
+		if equal(withoutName(oldPkg), withoutName(newPkg)) {
+			return newName, true
+		}
+	}
+	return "", false
+}
+
+func hasAlias(pkg *registry.PackageInfo, name string) bool {
+	for _, alias := range pkg.Aliases {
+		if alias.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutName returns a copy of the package without fields changed by renaming.
+func withoutName(pkg *registry.PackageInfo) *registry.PackageInfo {
+	p := *pkg
+	p.Name = ""
+	p.RepoOwner = ""
+	p.RepoName = ""
+	p.Aliases = nil
+	return &p
+}
+
+// equal compares packages semantically, so changes of comments and formats are ignored.
+func equal(a, b *registry.PackageInfo) bool {
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ab) == string(bb)
+}
@@ -0,0 +1,106 @@
+package changelog_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/changelog"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func writePkg(t *testing.T, dir, pkgName, content string) {
+	t.Helper()
+	p := filepath.Join(dir, "pkgs", filepath.FromSlash(pkgName), "registry.yaml")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+}
+
+func githubRelease(owner, name, extra string) string {
+	return "packages:\n  - type: github_release\n    repo_owner: " + owner + "\n    repo_name: " + name + "\n" + extra
+}
+
+func commit(t *testing.T, dir, msg string) {
+	t.Helper()
+	testutil.Git(t, dir, "add", "-A")
+	testutil.Git(t, dir, "commit", "-m", msg)
+	testutil.Git(t, dir, "tag", msg)
+}
+
+func TestGenerate(t *testing.T) {
+	testutil.SetGitEnv(t)
+	dir := t.TempDir()
+	testutil.Git(t, dir, "init", "-b", "main")
+	writePkg(t, dir, "cli/cli", githubRelease("cli", "cli", ""))
+	writePkg(t, dir, "hashicorp/vault", githubRelease("hashicorp", "vault", ""))
+	writePkg(t, dir, "Azure/aztfy", githubRelease("Azure", "aztfy", ""))
+	writePkg(t, dir, "foo/old", githubRelease("foo", "old", "    asset: foo_{{.OS}}.tar.gz\n"))
+	writePkg(t, dir, "suzuki-shunsuke/tfcmt", githubRelease("suzuki-shunsuke", "tfcmt", ""))
+	commit(t, dir, "v1")
+
+	writePkg(t, dir, "cli/cli", githubRelease("cli", "cli", "    description: GitHub CLI\n"))
+	if err := os.RemoveAll(filepath.Join(dir, "pkgs", "hashicorp")); err != nil {
+		t.Fatal(err)
+	}
+	testutil.Git(t, dir, "mv", "pkgs/Azure/aztfy", "pkgs/Azure/aztfexport")
+	writePkg(t, dir, "Azure/aztfexport", githubRelease("Azure", "aztfexport", "    aliases:\n      - name: Azure/aztfy\n"))
+	testutil.Git(t, dir, "mv", "pkgs/foo/old", "pkgs/foo/new")
+	writePkg(t, dir, "foo/new", githubRelease("foo", "new", "    asset: foo_{{.OS}}.tar.gz\n"))
+	writePkg(t, dir, "suzuki-shunsuke/tfcmt", "# comment\n"+githubRelease("suzuki-shunsuke", "tfcmt", ""))
+	writePkg(t, dir, "suzuki-shunsuke/pinact", githubRelease("suzuki-shunsuke", "pinact", ""))
+	commit(t, dir, "v2")
+
+	c, err := changelog.Generate(t.Context(), git.New(slog.New(slog.DiscardHandler), dir), "v1", "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &changelog.Changelog{
+		From:    "v1",
+		To:      "v2",
+		Added:   []string{"suzuki-shunsuke/pinact"},
+		Removed: []string{"hashicorp/vault"},
+		Renamed: []*changelog.Rename{
+			{From: "Azure/aztfy", To: "Azure/aztfexport"},
+			{From: "foo/old", To: "foo/new"},
+		},
+		Modified: []string{"cli/cli"},
+	}
+	if diff := cmp.Diff(exp, c); diff != "" {
+		t.Fatalf("changelog (-want +got):\n%s", diff)
+	}
+
+	buf := &strings.Builder{}
+	if err := changelog.WriteMarkdown(buf, c); err != nil {
+		t.Fatal(err)
+	}
+	expMD := `## Changes of packages (v1...v2)
+
+### Added (1)
+
+- suzuki-shunsuke/pinact
+
+### Removed (1)
+
+- hashicorp/vault
+
+### Renamed (2)
+
+- Azure/aztfy → Azure/aztfexport
+- foo/old → foo/new
+
+### Modified (1)
+
+- cli/cli
+`
+	if buf.String() != expMD {
+		t.Fatalf("wanted\n%s\ngot\n%s", expMD, buf.String())
+	}
+}
@@ -0,0 +1,55 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteJSON outputs the changelog as JSON.
+func WriteJSON(w io.Writer, c *Changelog) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("encode the changelog as JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown outputs the changelog as Markdown.
+// Empty sections are omitted.
+func WriteMarkdown(w io.Writer, c *Changelog) error {
+	if _, err := fmt.Fprintf(w, "## Changes of packages (%s...%s)\n", c.From, c.To); err != nil {
+		return fmt.Errorf("write the changelog: %w", err)
+	}
+	if c.Empty() {
+		_, err := fmt.Fprintln(w, "\nNo package is changed.")
+		return err //nolint:wrapcheck
+	}
+	renamed := make([]string, len(c.Renamed))
+	for i, r := range c.Renamed {
+		renamed[i] = r.From + " → " + r.To
+	}
+	for _, section := range []struct {
+		title string
+		names []string
+	}{
+		{"Added", c.Added},
+		{"Removed", c.Removed},
+		{"Renamed", renamed},
+		{"Modified", c.Modified},
+	} {
+		if len(section.names) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n### %s (%d)\n\n", section.title, len(section.names)); err != nil {
+			return fmt.Errorf("write the changelog: %w", err)
+		}
+		for _, name := range section.names {
+			if _, err := fmt.Fprintf(w, "- %s\n", name); err != nil {
+				return fmt.Errorf("write the changelog: %w", err)
+			}
+		}
+	}
+	return nil
+}
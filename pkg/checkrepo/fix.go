@@ -0,0 +1,99 @@
+package checkrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+
+	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/mv"
+	"github.com/spf13/afero"
+)
+
+// FixConfig is the configuration of Fix.
+type FixConfig struct {
+	HTTPClient *http.Client
+	// BaseURL is the URL of GitHub such as https://github.com.
+	BaseURL string
+	Repo    git.Repo
+	// Commit is options of git commit.
+	// If it's nil, changes aren't committed.
+	// Message is set by Fix.
+	Commit *git.CommitOptions
+}
+
+// Fix migrates the package to the new repository if the repository of the package was transferred.
+// The package is renamed by mv.Move, which regenerates registry.yaml.
+// If the package name doesn't depend on the repository, only repo_owner and repo_name are changed.
+// It does nothing if the repository wasn't transferred.
+// If changes are committed, Fix fails when the index already has staged changes
+// so that unrelated changes aren't committed together.
+func Fix(ctx context.Context, logger *slog.Logger, afs afero.Fs, cfg *FixConfig, pkgName string) error {
+	redirect, err := CheckRedirect(ctx, afs, cfg.HTTPClient, cfg.BaseURL, pkgName)
+	if err != nil {
+		return err
+	}
+	if redirect == nil {
+		return nil
+	}
+	if cfg.Commit != nil {
+		if err := checkStaged(ctx, cfg.Repo); err != nil {
+			return err
+		}
+	}
+	logger.Info("a repository was transferred",
+		"package_name", pkgName,
+		"new_package_name", redirect.NewPackageName,
+		"repo_owner", redirect.NewRepoOwner,
+		"repo_name", redirect.NewRepoName,
+	)
+	if err := mv.Move(ctx, logger, cfg.Repo, pkgName, redirect.NewPackageName, &mv.Options{
+		RepoOwner: redirect.NewRepoOwner,
+		RepoName:  redirect.NewRepoName,
+	}); err != nil {
+		return fmt.Errorf("move the package: %w", err)
+	}
+	fmt.Printf("%s/%s\n", redirect.NewRepoOwner, redirect.NewRepoName) //nolint:forbidigo
+	if cfg.Commit == nil {
+		return nil
+	}
+	// Stage the package and all files generated by mv.Move
+	genOpts, err := genrg.OptionsFromConfig()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	paths := append([]string{path.Join("pkgs", redirect.NewPackageName)}, genOpts.Paths()...)
+	if err := cfg.Repo.Add(ctx, paths...); err != nil {
+		return fmt.Errorf("stage changes: %w", err)
+	}
+	commitOpts := *cfg.Commit
+	commitOpts.Message = commitMessage(pkgName, redirect)
+	if err := cfg.Repo.Commit(ctx, &commitOpts); err != nil {
+		return fmt.Errorf("commit changes: %w", err)
+	}
+	return nil
+}
+
+// checkStaged returns an error if the index has staged changes.
+func checkStaged(ctx context.Context, repo git.Repo) error {
+	st, err := repo.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("get the status of the repository: %w", err)
+	}
+	if len(st.Staged) > 0 {
+		return errors.New("there are staged changes, so commit or unstage them first")
+	}
+	return nil
+}
+
+func commitMessage(pkgName string, redirect *Redirect) string {
+	transfer := fmt.Sprintf("%s/%s was transferred to %s/%s", redirect.RepoOwner, redirect.RepoName, redirect.NewRepoOwner, redirect.NewRepoName)
+	if pkgName == redirect.NewPackageName {
+		return fmt.Sprintf("fix(%s): update the repository\n\n%s", pkgName, transfer)
+	}
+	return fmt.Sprintf("fix: rename %s to %s\n\n%s", pkgName, redirect.NewPackageName, transfer)
+}
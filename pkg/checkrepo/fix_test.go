@@ -0,0 +1,151 @@
+package checkrepo_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/checkrepo"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+// newServer returns a server standing in for github.com.
+// suzuki-shunsuke/tfcmt was transferred to tfcmt/tfcmt.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/suzuki-shunsuke/tfcmt":
+			http.Redirect(w, r, "/tfcmt/tfcmt", http.StatusMovedPermanently)
+		case "/cli/cli":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newFixConfig(srv *httptest.Server, commit *git.CommitOptions) *checkrepo.FixConfig {
+	return &checkrepo.FixConfig{
+		HTTPClient: &http.Client{
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		BaseURL: srv.URL,
+		Repo:    git.New(slog.New(slog.DiscardHandler), ""),
+		Commit:  commit,
+	}
+}
+
+func TestFix(t *testing.T) {
+	testutil.SetGitEnv(t)
+	t.Chdir(t.TempDir())
+	testutil.Git(t, "", "init", "-b", "main")
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.tar.gz
+`)
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/pkg.yaml", "packages:\n  - name: suzuki-shunsuke/tfcmt@v4.0.0\n")
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`)
+	testutil.WriteFile(t, "pkgs/cli/cli/pkg.yaml", "packages:\n  - name: cli/cli@v2.0.0\n")
+	testutil.WriteFile(t, "argd.yaml", "generate_registry:\n  json: registry.json\n  search_index: search-index.json\n")
+	testutil.Git(t, "", "add", ".")
+	testutil.Git(t, "", "commit", "-m", "init")
+
+	srv := newServer(t)
+	logger := slog.New(slog.DiscardHandler)
+
+	// The repository isn't transferred
+	if err := checkrepo.Fix(t.Context(), logger, afero.NewOsFs(), newFixConfig(srv, &git.CommitOptions{}), "cli/cli"); err != nil {
+		t.Fatal(err)
+	}
+	if s := testutil.Git(t, "", "status", "--short"); s != "" {
+		t.Fatalf("nothing should be changed: %s", s)
+	}
+
+	// Unrelated staged changes must not be committed
+	testutil.WriteFile(t, "pkgs/cli/cli/pkg.yaml", "packages:\n  - name: cli/cli@v2.1.0\n")
+	testutil.Git(t, "", "add", "pkgs/cli/cli/pkg.yaml")
+	if err := checkrepo.Fix(t.Context(), logger, afero.NewOsFs(), newFixConfig(srv, &git.CommitOptions{}), "suzuki-shunsuke/tfcmt"); err == nil || !strings.Contains(err.Error(), "staged changes") {
+		t.Fatalf("error should be returned because there are staged changes: %v", err)
+	}
+	if _, err := os.Stat("pkgs/suzuki-shunsuke/tfcmt/registry.yaml"); err != nil {
+		t.Fatalf("the package must not be moved: %v", err)
+	}
+	testutil.Git(t, "", "reset", "--hard")
+
+	if err := checkrepo.Fix(t.Context(), logger, afero.NewOsFs(), newFixConfig(srv, &git.CommitOptions{}), "suzuki-shunsuke/tfcmt"); err != nil {
+		t.Fatal(err)
+	}
+	exp := `packages:
+  - type: github_release
+    repo_owner: tfcmt
+    repo_name: tfcmt
+    aliases:
+      - name: suzuki-shunsuke/tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.tar.gz
+`
+	if diff := cmp.Diff(exp, testutil.ReadFile(t, "pkgs/tfcmt/tfcmt/registry.yaml")); diff != "" {
+		t.Fatalf("registry.yaml (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("packages:\n  - name: tfcmt/tfcmt@v4.0.0\n", testutil.ReadFile(t, "pkgs/tfcmt/tfcmt/pkg.yaml")); diff != "" {
+		t.Fatalf("pkg.yaml (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat("pkgs/suzuki-shunsuke"); !os.IsNotExist(err) {
+		t.Fatalf("the old directory must be removed: %v", err)
+	}
+	if s := testutil.ReadFile(t, "registry.yaml"); !strings.Contains(s, "repo_owner: tfcmt") {
+		t.Fatalf("registry.yaml must be regenerated: %s", s)
+	}
+	if s := testutil.Git(t, "", "show", "--name-only", "--format="); !strings.Contains(s, "registry.json") || !strings.Contains(s, "search-index.json") {
+		t.Fatalf("generated files must be committed: %s", s)
+	}
+	if s := testutil.Git(t, "", "status", "--short"); s != "" {
+		t.Fatalf("all changes must be committed: %s", s)
+	}
+	if s := testutil.Git(t, "", "log", "-1", "--format=%s"); s != "fix: rename suzuki-shunsuke/tfcmt to tfcmt/tfcmt" {
+		t.Fatalf("unexpected commit message: %s", s)
+	}
+}
+
+func TestFix_sameName(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Chdir(t.TempDir())
+	testutil.Git(t, "", "init", "-b", "main")
+	testutil.WriteFile(t, "pkgs/tfcmt/registry.yaml", `packages:
+  - name: tfcmt
+    type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+`)
+	testutil.WriteFile(t, "pkgs/tfcmt/pkg.yaml", "packages:\n  - name: tfcmt@v4.0.0\n")
+
+	srv := newServer(t)
+	if err := checkrepo.Fix(t.Context(), slog.New(slog.DiscardHandler), afero.NewOsFs(), newFixConfig(srv, nil), "tfcmt"); err != nil {
+		t.Fatal(err)
+	}
+	exp := `packages:
+  - name: tfcmt
+    type: github_release
+    repo_owner: tfcmt
+    repo_name: tfcmt
+`
+	if diff := cmp.Diff(exp, testutil.ReadFile(t, "pkgs/tfcmt/registry.yaml")); diff != "" {
+		t.Fatalf("registry.yaml (-want +got):\n%s", diff)
+	}
+}
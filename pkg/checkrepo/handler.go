@@ -27,8 +27,10 @@ type Alias struct {
 	Name string
 }
 
-func CheckRepo(ctx context.Context, afs afero.Fs, httpClient *http.Client, pkgName string) error {
-	redirect, err := CheckRedirect(ctx, afs, httpClient, pkgName)
+// CheckRepo checks if the repository of the package was transferred.
+// baseURL is the URL of GitHub such as https://github.com.
+func CheckRepo(ctx context.Context, afs afero.Fs, httpClient *http.Client, baseURL, pkgName string) error {
+	redirect, err := CheckRedirect(ctx, afs, httpClient, baseURL, pkgName)
 	if err != nil {
 		return err
 	}
@@ -52,7 +54,9 @@ type Redirect struct {
 	NewPackageName string
 }
 
-func CheckRedirect(ctx context.Context, afs afero.Fs, httpClient *http.Client, pkgName string) (*Redirect, error) { //nolint:cyclop
+// CheckRedirect returns the new repository if the repository of the package was transferred.
+// It returns nil if the repository wasn't transferred.
+func CheckRedirect(ctx context.Context, afs afero.Fs, httpClient *http.Client, baseURL, pkgName string) (*Redirect, error) { //nolint:cyclop
 	registryPath := filepath.Join("pkgs", filepath.FromSlash(pkgName), "registry.yaml")
 	f, err := afero.ReadFile(afs, registryPath)
 	if err != nil {
@@ -70,7 +74,7 @@ func CheckRedirect(ctx context.Context, afs afero.Fs, httpClient *http.Client, p
 		return nil, nil //nolint:nilnil
 	}
 
-	resp, err := request(ctx, httpClient, pkg.RepoOwner, pkg.RepoName)
+	resp, err := request(ctx, httpClient, baseURL, pkg.RepoOwner, pkg.RepoName)
 	if err != nil {
 		return nil, err
 	}
@@ -115,8 +119,8 @@ func CheckRedirect(ctx context.Context, afs afero.Fs, httpClient *http.Client, p
 	return redirect, nil
 }
 
-func request(ctx context.Context, httpClient *http.Client, repoOwner, repoName string) (*http.Response, error) {
-	u := fmt.Sprintf("https://github.com/%s/%s", repoOwner, repoName)
+func request(ctx context.Context, httpClient *http.Client, baseURL, repoOwner, repoName string) (*http.Response, error) {
+	u := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(baseURL, "/"), repoOwner, repoName)
 	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create a http request: %w", err)
@@ -0,0 +1,58 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aquaproj/registry-tool/pkg/changelog"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/urfave/cli/v3"
+)
+
+func Command(logger *slog.Logger) *cli.Command {
+	var format string
+	return &cli.Command{
+		Name:      "changelog",
+		Usage:     "List packages changed between two git revisions",
+		UsageText: "argd changelog [--format markdown|json] <from> <to>",
+		Description: `List packages added, removed, renamed, and modified between two git revisions.
+
+This command reads pkgs/**/registry.yaml at both revisions from git objects, so the working tree isn't changed.
+A removed package is regarded as renamed if another package has it as a new alias,
+or if an added package is same as it except for the name and either the owner or the repository name is kept.
+Changes of comments and formats aren't regarded as modifications.
+
+e.g.
+
+$ argd changelog v4.300.0 v4.301.0
+$ argd changelog --format json v4.300.0 HEAD
+`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output format. markdown or json",
+				Value:       "markdown",
+				Destination: &format,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if cmd.Args().Len() != 2 { //nolint:mnd
+				return errors.New("two revisions are required")
+			}
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("format must be markdown or json: %s", format)
+			}
+			c, err := changelog.Generate(ctx, git.New(logger, ""), cmd.Args().Get(0), cmd.Args().Get(1))
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+			if format == "json" {
+				return changelog.WriteJSON(os.Stdout, c) //nolint:wrapcheck
+			}
+			return changelog.WriteMarkdown(os.Stdout, c) //nolint:wrapcheck
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/pkgyaml"
+	"github.com/urfave/cli/v3"
+)
+
+func Command(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "check",
+		Usage: "Check packages in pkgs",
+		Commands: []*cli.Command{
+			namesCommand(),
+			pkgYAMLCommand(logger),
+		},
+	}
+}
+
+func namesCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "names",
+		Usage:     "Check if package names and aliases conflict",
+		UsageText: "argd check names",
+		Description: `Check if package names and aliases conflict.
+
+This command builds the index of names and aliases of all packages in pkgs,
+and outputs collisions with locations of both sides.
+Names are compared case-insensitively.
+Names of the same package don't conflict, so an alias differing from the package name only in case is allowed.
+Aliases declared more than once in a package are reported as duplicates.
+This command fails if any collision or duplicate is found.
+argd gr also fails if any collision is found.
+
+No argument is needed.
+`,
+		Action: func(ctx context.Context, _ *cli.Command) error {
+			return genrg.CheckNames(ctx, os.Stdout)
+		},
+	}
+}
+
+func pkgYAMLCommand(logger *slog.Logger) *cli.Command {
+	cfg := &pkgyaml.Config{
+		Stdout: os.Stdout,
+	}
+	var releases bool
+	return &cli.Command{
+		Name:      "pkg-yaml",
+		Usage:     "Check consistency between pkg.yaml and registry.yaml",
+		UsageText: "argd check pkg-yaml [--fix] [--releases] [<package name> ...]",
+		Description: `Check consistency between pkgs/**/pkg.yaml and registry.yaml.
+
+This command checks the following things:
+
+- Package names in pkg.yaml are the package name or its aliases
+- Versions are specified
+- Entries aren't duplicated
+- The latest version is selected by the last version_overrides entry,
+  otherwise the entry isn't tested
+
+With --releases, versions in pkg.yaml must exist in GitHub Releases (or tags if version_source is github_tag).
+If no argument is given, all packages having pkg.yaml are checked.
+This command fails if any problem is found.
+
+With --fix, pkg.yaml is normalized before checking it.
+Duplicated entries are removed and entries are sorted from newest to oldest.
+The newest entry is written as <package name>@<version> so that Renovate updates it,
+and other entries have the field version.
+pkg.yaml is rewritten from scratch, so comments and fields other than name and version are discarded.
+
+e.g.
+
+$ argd check pkg-yaml
+$ argd check pkg-yaml --fix cli/cli
+$ argd check pkg-yaml --releases cli/cli
+`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "fix",
+				Usage:       "Normalize and sort pkg.yaml. Comments and fields other than name and version are discarded",
+				Destination: &cfg.Fix,
+			},
+			&cli.BoolFlag{
+				Name:        "releases",
+				Usage:       "Check if versions exist in GitHub Releases or tags",
+				Destination: &releases,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			cfg.Args = cmd.Args().Slice()
+			if releases {
+				argdCfg, err := config.Read()
+				if err != nil {
+					return fmt.Errorf("read the configuration file: %w", err)
+				}
+				gh, err := github.New(ctx, logger, argdCfg.GitHub)
+				if err != nil {
+					return fmt.Errorf("create github client: %w", err)
+				}
+				defer gh.LogStats()
+				cfg.Versions = gh
+			}
+			return pkgyaml.Run(ctx, logger, cfg) //nolint:wrapcheck
+		},
+	}
+}
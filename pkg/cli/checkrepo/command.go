@@ -2,17 +2,29 @@ package checkrepo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/aquaproj/registry-tool/pkg/checkrepo"
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/github"
 	"github.com/spf13/afero"
 	"github.com/urfave/cli/v3"
 )
 
-type runner struct{}
+type runner struct {
+	logger *slog.Logger
+	fix    bool
+	commit bool
+}
 
-func Command() *cli.Command {
-	r := &runner{}
+func Command(logger *slog.Logger) *cli.Command {
+	r := &runner{
+		logger: logger,
+	}
 	return r.Command()
 }
 
@@ -20,25 +32,70 @@ func (r *runner) Command() *cli.Command {
 	return &cli.Command{
 		Name:      "check-repo",
 		Usage:     `Check if GitHub Repository was transferred`,
-		UsageText: `$ argd check-repo <package name>`,
+		UsageText: `$ argd check-repo [--fix [--commit]] <package name>`,
 		Description: `Check if GitHub Repository is transferred.
 This command succeeds if the repository isn't transferred.
 
+With --fix, the package is migrated to the new repository instead of failing.
+The package is renamed as argd mv does, and registry.yaml is regenerated.
+If the package name doesn't depend on the repository, only repo_owner and repo_name are changed.
+With --commit, changes are committed, including registry.json and the search index configured in argd.yaml.
+--commit fails if there are already staged changes.
+The new repository is output as <repo_owner>/<repo_name> in the same way as without --fix.
+
 e.g.
 
 $ argd check-repo Azure/aztfy
 Azure/aztfexport
+
+$ argd check-repo --fix --commit Azure/aztfy
 `,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "fix",
+				Usage:       "Migrate the package to the new repository",
+				Destination: &r.fix,
+			},
+			&cli.BoolFlag{
+				Name:        "commit",
+				Usage:       "Commit changes made by --fix",
+				Destination: &r.commit,
+			},
+		},
 		Action: r.action,
 	}
 }
 
 func (r *runner) action(ctx context.Context, cmd *cli.Command) error {
-	return checkrepo.CheckRepo( //nolint:wrapcheck
-		ctx, afero.NewOsFs(), &http.Client{
-			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
+	if r.commit && !r.fix {
+		return errors.New("--commit requires --fix")
+	}
+	cfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("read the configuration file: %w", err)
+	}
+	// Requests to GitHub aren't authenticated and aren't cached, but they are retried when rate limited
+	transport := github.NewTransport(r.logger, nil, "", "")
+	defer transport.LogStats()
+	httpClient := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
 		},
+	}
+	if r.fix {
+		fixCfg := &checkrepo.FixConfig{
+			HTTPClient: httpClient,
+			BaseURL:    cfg.GitHub.BaseURL,
+			Repo:       git.New(r.logger, ""),
+		}
+		if r.commit {
+			fixCfg.Commit = cfg.Git.CommitOptions("")
+		}
+		return checkrepo.Fix(ctx, r.logger, afero.NewOsFs(), fixCfg, cmd.Args().First()) //nolint:wrapcheck
+	}
+	return checkrepo.CheckRepo( //nolint:wrapcheck
+		ctx, afero.NewOsFs(), httpClient,
+		cfg.GitHub.BaseURL,
 		cmd.Args().First())
 }
@@ -0,0 +1,55 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/doctor"
+	"github.com/urfave/cli/v3"
+)
+
+func Command(logger *slog.Logger) *cli.Command {
+	var jsonOutput bool
+	return &cli.Command{
+		Name:      "doctor",
+		Usage:     "Diagnose the environment to develop aqua Registry",
+		UsageText: "argd doctor [--json]",
+		Description: `Check the environment and show how to fix problems.
+
+* Docker or Podman is installed and the daemon is reachable
+* aqua and gh meet the minimum versions
+* aqua/dev.yaml and Dockerfiles under docker/ exist
+* the remote origin points at a fork rather than the upstream repository
+* a GitHub access token is found
+* enough disk space is free
+
+This command fails if any check fails.
+
+e.g.
+
+$ argd doctor
+$ argd doctor --json
+`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "json",
+				Usage:       "Output results as JSON",
+				Destination: &jsonOutput,
+			},
+		},
+		Action: func(ctx context.Context, _ *cli.Command) error {
+			cfg, err := config.Read()
+			if err != nil {
+				return fmt.Errorf("read the configuration file: %w", err)
+			}
+			results := doctor.New(logger, cfg.Git).Run(ctx)
+			if jsonOutput {
+				return doctor.PrintJSON(os.Stdout, results) //nolint:wrapcheck
+			}
+			return doctor.Print(os.Stdout, results) //nolint:wrapcheck
+		},
+	}
+}
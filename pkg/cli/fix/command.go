@@ -3,18 +3,65 @@ package fix
 import (
 	"context"
 	"log/slog"
+	"os"
 
 	"github.com/aquaproj/registry-tool/pkg/fix"
+	"github.com/aquaproj/registry-tool/pkg/git"
 	"github.com/urfave/cli/v3"
 )
 
 func Command(logger *slog.Logger) *cli.Command {
+	opts := &fix.Options{
+		Stdout: os.Stdout,
+	}
 	return &cli.Command{
 		Name:      "fix",
-		Usage:     "Fix a package",
-		UsageText: "argd fix [<package name> or pkgs/**/pkg.yaml or pkgs/**/registry.yaml] ...",
+		Usage:     "Fix packages",
+		UsageText: "argd fix [--all | --changed-since <ref>] [--check] [-j <workers>] [<package name> or pkgs/**/pkg.yaml or pkgs/**/registry.yaml] ...",
+		Description: `Fix pkgs/**/registry.yaml with rules of argd lint which can fix themselves.
+Only rules whose fixes keep the behavior of aqua are applied:
+exe-suffix, redundant-name, redundant-override, identity-replacement, redundant-supported-envs, and redundant-files.
+pkg.yaml isn't needed, so an invalid pkg.yaml is reported as a warning.
+
+Paths to changed files are output.
+With --all, all pkgs/**/registry.yaml are fixed.
+With --changed-since, pkgs/**/registry.yaml changed from the given revision and untracked ones are fixed.
+Files are fixed concurrently.
+With --check, files aren't written, and this command fails if any file would be changed.
+
+e.g.
+
+$ argd fix cli/cli
+$ argd fix --all
+$ argd fix --changed-since origin/main
+$ argd fix --all --check
+`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "all",
+				Usage:       "Fix all packages",
+				Destination: &opts.All,
+			},
+			&cli.StringFlag{
+				Name:        "changed-since",
+				Usage:       "Fix packages changed from the revision",
+				Destination: &opts.ChangedSince,
+			},
+			&cli.BoolFlag{
+				Name:        "check",
+				Usage:       "Fail if any file would be changed without writing files",
+				Destination: &opts.Check,
+			},
+			&cli.IntFlag{
+				Name:        "workers",
+				Aliases:     []string{"j"},
+				Usage:       "The number of files fixed concurrently. The default is the number of CPUs",
+				Destination: &opts.Workers,
+			},
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			return fix.Fix(ctx, logger, cmd.Args().Slice())
+			opts.Repo = git.New(logger, "")
+			return fix.Fix(ctx, logger, opts, cmd.Args().Slice()) //nolint:wrapcheck
 		},
 	}
 }
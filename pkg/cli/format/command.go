@@ -0,0 +1,50 @@
+package format
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/aquaproj/registry-tool/pkg/format"
+	"github.com/urfave/cli/v3"
+)
+
+func Command(logger *slog.Logger) *cli.Command {
+	cfg := &format.Config{
+		Stdout: os.Stdout,
+	}
+	return &cli.Command{
+		Name:      "fmt",
+		Usage:     "Format registry.yaml",
+		UsageText: "argd fmt [--check] [<package name> or pkgs/**/registry.yaml] ...",
+		Description: `Format pkgs/**/registry.yaml.
+
+Keys of packages, version_overrides, overrides, files, and format_overrides are sorted in the canonical order.
+Keys of packages start with type, repo_owner, repo_name, and description, and the rest follows the order of aqua.
+e.g. type, repo_owner, repo_name, description, name, aliases, search_words, link, asset, ...
+Unknown keys are put after known keys.
+Indentation and quotes are normalized, and comments are kept.
+
+If no argument is given, all pkgs/**/registry.yaml are formatted.
+Paths to changed files are output.
+With --check, files aren't written, and this command fails if any file isn't formatted.
+
+e.g.
+
+$ argd fmt
+$ argd fmt cli/cli
+$ argd fmt --check
+`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "check",
+				Usage:       "Fail if any file isn't formatted without writing files",
+				Destination: &cfg.Check,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			cfg.Args = cmd.Args().Slice()
+			return format.Run(ctx, logger, cfg) //nolint:wrapcheck
+		},
+	}
+}
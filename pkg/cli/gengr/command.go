@@ -2,26 +2,107 @@ package gengr
 
 import (
 	"context"
+	"os"
 
 	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
 	"github.com/urfave/cli/v3"
 )
 
 func Command() *cli.Command {
+	var check bool
+	opts := &genrg.Options{}
 	return &cli.Command{
 		Name:      "generate-registry",
 		Aliases:   []string{"gr"},
 		Usage:     `Update registry.yaml`,
-		UsageText: `argd gr`,
+		UsageText: `argd gr [--check] [--json <path>] [--search-index <path>] [--include <glob>]... [--exclude <glob>]... [--from-list <file>] [-o <path>]`,
 		Description: `Update registry.yaml
 
 This command updates registry.yaml on the repository root directory.
 Don't edit it manually, and if you update registry.yaml in the pkgs directory, don't forget to run this command.
 
+Each pkgs/**/registry.yaml must have only one package, and the package name must match the path.
+Otherwise this command fails with the file and line.
+This command also fails if package names and aliases conflict. Please see argd check names.
+
 No argument is needed.
+
+With --check, this command doesn't update registry.yaml but checks if it's up to date.
+If it's outdated, this command outputs the diff and packages whose fragments differ including removed packages, and fails.
+This is useful in CI.
+
+$ argd gr --check
+
+With --json and --search-index, this command also generates registry.json and a search index.
+The search index is a JSON array of packages with the name, aliases, description, repository, and type.
+You can also specify them with the configuration file argd.yaml.
+They are generated from argd.yaml only when the full registry.yaml is generated, not a subset.
+Other commands updating registry.yaml such as argd scaffold and argd mv also generate files configured in argd.yaml.
+
+generate_registry:
+  json: registry.json
+  search_index: search-index.json
+
+$ argd gr --json registry.json --search-index search-index.json
+
+With --include, --exclude, --from-list, and -o, this command generates a registry including a subset of packages.
+--include and --exclude are glob patterns matched with package names and aliases. e.g. "suzuki-shunsuke/*"
+--from-list is a file including a package name or an alias per line. Empty lines and lines starting with # are ignored.
+If neither --include nor --from-list is set, all packages are included before --exclude is applied.
+--check also works with them.
+
+$ argd gr --from-list packages.txt --exclude "hashicorp/*" -o internal/registry.yaml
+$ argd gr --check --from-list packages.txt --exclude "hashicorp/*" -o internal/registry.yaml
 `,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "check",
+				Usage:       "Check if registry.yaml is up to date without updating it",
+				Destination: &check,
+			},
+			&cli.StringFlag{
+				Name:        "json",
+				Usage:       "The path to registry.json. If it's set, registry.json is generated",
+				Destination: &opts.JSON,
+			},
+			&cli.StringFlag{
+				Name:        "search-index",
+				Usage:       "The path to the search index. If it's set, the search index is generated",
+				Destination: &opts.SearchIndex,
+			},
+			&cli.StringSliceFlag{
+				Name:        "include",
+				Usage:       "A glob pattern of packages to include",
+				Destination: &opts.Include,
+			},
+			&cli.StringSliceFlag{
+				Name:        "exclude",
+				Usage:       "A glob pattern of packages to exclude",
+				Destination: &opts.Exclude,
+			},
+			&cli.StringFlag{
+				Name:        "from-list",
+				Usage:       "The path to a file including a package name per line to include",
+				Destination: &opts.FromList,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       "The path to the generated registry",
+				Value:       "registry.yaml",
+				Destination: &opts.Output,
+			},
+		},
 		Action: func(ctx context.Context, _ *cli.Command) error {
-			return genrg.GenerateRegistry(ctx)
+			cfgOpts, err := genrg.OptionsFromConfig()
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+			opts.SetConfigOutputs(cfgOpts)
+			if check {
+				return genrg.CheckRegistry(ctx, os.Stdout, opts)
+			}
+			return genrg.GenerateRegistry(ctx, opts)
 		},
 	}
 }
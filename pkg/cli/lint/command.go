@@ -0,0 +1,91 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/lint"
+	"github.com/urfave/cli/v3"
+)
+
+func Command(logger *slog.Logger) *cli.Command {
+	cfg := &lint.Config{
+		Stdout: os.Stdout,
+	}
+	var listRules, releases bool
+	return &cli.Command{
+		Name:      "lint",
+		Usage:     "Lint packages",
+		UsageText: "argd lint [--format text|json|sarif] [--fix] [--releases] [--rules] [<package name> or pkgs/**/registry.yaml] ...",
+		Description: `Lint pkgs/**/registry.yaml with rules.
+
+If no argument is given, all pkgs/**/registry.yaml are linted.
+Violations are output with the positions in the format file:line:col.
+With --format sarif, violations are output in SARIF, which can be uploaded to GitHub Code Scanning.
+With --fix, violations of rules which can fix themselves are fixed, and only violations which remain are output.
+version_overrides are checked with versions in pkg.yaml, including versions of aliases of the package.
+With --releases, they are also checked with GitHub Releases of packages.
+This command fails if any error or warning is found.
+Info violations are output but don't make this command fail.
+
+--rules lists rules.
+
+e.g.
+
+$ argd lint
+$ argd lint cli/cli
+$ argd lint --fix pkgs/cli/cli/registry.yaml
+$ argd lint --format sarif > lint.sarif
+$ argd lint --releases cli/cli
+`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output format. text, json, or sarif",
+				Value:       lint.FormatText,
+				Destination: &cfg.Format,
+			},
+			&cli.BoolFlag{
+				Name:        "fix",
+				Usage:       "Fix violations",
+				Destination: &cfg.Fix,
+			},
+			&cli.BoolFlag{
+				Name:        "releases",
+				Usage:       "Check version_overrides with GitHub Releases",
+				Destination: &releases,
+			},
+			&cli.BoolFlag{
+				Name:        "rules",
+				Usage:       "List rules",
+				Destination: &listRules,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if listRules {
+				for _, rule := range lint.Rules() {
+					fmt.Fprintf(os.Stdout, "%s (%s)\n%s\n\n", rule.ID(), rule.Severity(), rule.Doc())
+				}
+				return nil
+			}
+			cfg.Args = cmd.Args().Slice()
+			if releases {
+				argdCfg, err := config.Read()
+				if err != nil {
+					return fmt.Errorf("read the configuration file: %w", err)
+				}
+				gh, err := github.New(ctx, logger, argdCfg.GitHub)
+				if err != nil {
+					return fmt.Errorf("create github client: %w", err)
+				}
+				defer gh.LogStats()
+				cfg.Releases = gh
+			}
+			return lint.Run(ctx, logger, cfg) //nolint:wrapcheck
+		},
+	}
+}
@@ -7,7 +7,8 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/aquaproj/aqua/v2/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/github"
 	"github.com/aquaproj/registry-tool/pkg/naming"
 	"github.com/urfave/cli/v3"
 )
@@ -84,10 +85,15 @@ func Command(logger *slog.Logger) *cli.Command {
 			default:
 				return errors.New("usage: argd list-assets [owner/repo] <version>")
 			}
-			gh, err := github.New(ctx, logger)
+			cfg, err := config.Read()
+			if err != nil {
+				return fmt.Errorf("read the configuration file: %w", err)
+			}
+			gh, err := github.New(ctx, logger, cfg.GitHub)
 			if err != nil {
 				return fmt.Errorf("create github client: %w", err)
 			}
+			defer gh.LogStats()
 			return listAssets(ctx, gh, owner, name, version)
 		},
 	}
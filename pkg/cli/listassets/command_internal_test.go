@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/aquaproj/aqua/v2/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/github"
 )
 
 type mockGH struct {
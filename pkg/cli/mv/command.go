@@ -3,24 +3,50 @@ package mv
 import (
 	"context"
 	"errors"
+	"log/slog"
 
+	"github.com/aquaproj/registry-tool/pkg/git"
 	"github.com/aquaproj/registry-tool/pkg/mv"
-	"github.com/spf13/afero"
 	"github.com/urfave/cli/v3"
 )
 
-func Command() *cli.Command {
+func Command(logger *slog.Logger) *cli.Command {
+	opts := &mv.Options{}
 	return &cli.Command{
-		Name:        "mv",
-		Usage:       `Rename a package`,
-		UsageText:   `$ argd mv <old package name> <new package name>`,
-		Description: `Rename a package.`,
+		Name:      "mv",
+		Usage:     `Rename a package`,
+		UsageText: `$ argd mv [--keep-repo] <old package name> <new package name>`,
+		Description: `Rename a package.
+
+Files of the package are moved to the new directory, and registry.yaml and pkg.yaml are updated.
+repo_owner and repo_name are changed to the first two segments of the new package name.
+If the new package name isn't <repo_owner>/<repo_name>, the field name is set.
+repo_owner and repo_name aren't changed with --keep-repo or if the new package name is domain-style like example.com/foo/bar.
+If the command name would change, the field files is added to keep it.
+The old package name is added to aliases, and the new package name is removed from aliases.
+This command fails if the new package already exists or the new package name is already a name or an alias of another package.
+All changes are applied or none of them are applied.
+Tracked files are moved by git mv so that their history follows them.
+Empty directories of the old package are removed, and registry.yaml is regenerated.
+
+e.g.
+
+$ argd mv suzuki-shunsuke/tfcmt tfcmt/tfcmt
+$ argd mv hashicorp/terraform hashicorp/terraform/cli
+$ argd mv --keep-repo kubernetes/kubectl kubernetes.io/kubectl`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "keep-repo",
+				Usage:       "Keep repo_owner and repo_name",
+				Destination: &opts.KeepRepo,
+			},
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			args := cmd.Args().Slice()
 			if len(args) != 2 { //nolint:mnd
 				return errors.New("invalid arguments")
 			}
-			return mv.Move(ctx, afero.NewOsFs(), args[0], args[1])
+			return mv.Move(ctx, logger, git.New(logger, ""), args[0], args[1], opts)
 		},
 	}
 }
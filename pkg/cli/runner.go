@@ -3,13 +3,18 @@ package cli
 import (
 	"context"
 
+	changelogcmd "github.com/aquaproj/registry-tool/pkg/cli/changelog"
+	"github.com/aquaproj/registry-tool/pkg/cli/check"
 	"github.com/aquaproj/registry-tool/pkg/cli/checkrepo"
 	connectcmd "github.com/aquaproj/registry-tool/pkg/cli/connect"
 	"github.com/aquaproj/registry-tool/pkg/cli/createprnewpkg"
+	doctorcmd "github.com/aquaproj/registry-tool/pkg/cli/doctor"
 	"github.com/aquaproj/registry-tool/pkg/cli/fix"
+	formatcmd "github.com/aquaproj/registry-tool/pkg/cli/format"
 	"github.com/aquaproj/registry-tool/pkg/cli/gengr"
 	"github.com/aquaproj/registry-tool/pkg/cli/gflag"
 	"github.com/aquaproj/registry-tool/pkg/cli/initcmd"
+	lintcmd "github.com/aquaproj/registry-tool/pkg/cli/lint"
 	listassetscmd "github.com/aquaproj/registry-tool/pkg/cli/listassets"
 	"github.com/aquaproj/registry-tool/pkg/cli/mv"
 	"github.com/aquaproj/registry-tool/pkg/cli/patchchecksum"
@@ -20,6 +25,7 @@ import (
 	startcmd "github.com/aquaproj/registry-tool/pkg/cli/start"
 	stopcmd "github.com/aquaproj/registry-tool/pkg/cli/stop"
 	testcmd "github.com/aquaproj/registry-tool/pkg/cli/test"
+	validatecmd "github.com/aquaproj/registry-tool/pkg/cli/validate"
 	"github.com/suzuki-shunsuke/slog-util/slogutil"
 	"github.com/suzuki-shunsuke/urfave-cli-v3-util/urfave"
 	"github.com/urfave/cli/v3"
@@ -47,8 +53,8 @@ func Run(ctx context.Context, logger *slogutil.Logger, env *urfave.Env) error {
 			initcmd.Command(),
 			patchchecksum.Command(logger.Logger),
 			listassetscmd.Command(logger.Logger),
-			checkrepo.Command(),
-			mv.Command(),
+			checkrepo.Command(logger.Logger),
+			mv.Command(logger.Logger),
 			fix.Command(logger.Logger),
 			connectcmd.Command(logger.Logger),
 			removecmd.Command(logger.Logger),
@@ -57,6 +63,12 @@ func Run(ctx context.Context, logger *slogutil.Logger, env *urfave.Env) error {
 			startcmd.Command(logger.Logger),
 			stopcmd.Command(logger.Logger),
 			testcmd.Command(logger.Logger),
+			doctorcmd.Command(logger.Logger),
+			check.Command(logger.Logger),
+			changelogcmd.Command(logger.Logger),
+			lintcmd.Command(logger.Logger),
+			validatecmd.Command(),
+			formatcmd.Command(logger.Logger),
 		},
 	}).Run(ctx, env.Args)
 }
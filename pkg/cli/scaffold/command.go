@@ -18,6 +18,11 @@ type scaffoldFlags struct {
 	Deep           bool
 	Recreate       bool
 	NoCreateBranch bool
+	Base           string
+	NoMerge        bool
+	Interactive    bool
+	Update         bool
+	RequireToken   bool
 }
 
 const scaffoldDescription = `Scaffold a package.
@@ -25,6 +30,18 @@ const scaffoldDescription = `Scaffold a package.
 e.g.
 
 $ argd scaffold cli/cli
+
+If pkgs/<package name>/registry.yaml already exists, the output of aqua gr is merged into it
+so that hand edits such as description and files are kept.
+Keys changed both by hand and by aqua gr are written with conflict markers,
+or you can resolve them interactively with -i.
+The output of aqua gr is recorded in the git directory and used as the base of the next merge,
+so resolved conflicts don't come back.
+
+With --update, only releases newer than the last version_constraint boundary of the package are scaffolded.
+version_overrides for them are appended, and existing entries are kept as they are.
+
+$ argd scaffold --update cli/cli
 `
 
 func Command(logger *slog.Logger, gFlags *gflag.Flags) *cli.Command {
@@ -51,7 +68,12 @@ func Command(logger *slog.Logger, gFlags *gflag.Flags) *cli.Command {
 				Limit:          flags.Limit,
 				Recreate:       flags.Recreate,
 				NoCreateBranch: flags.NoCreateBranch,
+				Base:           flags.Base,
 				ConfigPath:     flags.Config,
+				NoMerge:        flags.NoMerge,
+				Interactive:    flags.Interactive,
+				Update:         flags.Update,
+				RequireToken:   flags.RequireToken,
 			}
 
 			return scaffold.Scaffold(ctx, logger, cfg)
@@ -89,11 +111,38 @@ func scaffoldCLIFlags(flags *scaffoldFlags) []cli.Flag {
 			Usage:       "Don't create a git branch",
 			Destination: &flags.NoCreateBranch,
 		},
+		&cli.StringFlag{
+			Name:        "base",
+			Usage:       "Create a git branch from the given ref without fetching the upstream repository",
+			Destination: &flags.Base,
+		},
 		&cli.StringFlag{
 			Name:        "config",
 			Aliases:     []string{"c"},
 			Usage:       "Path to scaffold.yaml configuration file",
 			Destination: &flags.Config,
 		},
+		&cli.BoolFlag{
+			Name:        "no-merge",
+			Usage:       "Overwrite the existing registry.yaml instead of merging the output of aqua gr into it",
+			Destination: &flags.NoMerge,
+		},
+		&cli.BoolFlag{
+			Name:        "interactive",
+			Aliases:     []string{"i"},
+			Usage:       "Resolve merge conflicts of registry.yaml interactively instead of writing conflict markers",
+			Destination: &flags.Interactive,
+		},
+		&cli.BoolFlag{
+			Name:        "update",
+			Aliases:     []string{"u"},
+			Usage:       "Scaffold only releases newer than the last version_constraint boundary and append version_overrides for them",
+			Destination: &flags.Update,
+		},
+		&cli.BoolFlag{
+			Name:        "require-token",
+			Usage:       "Fail if no GitHub access token is found",
+			Destination: &flags.RequireToken,
+		},
 	}
 }
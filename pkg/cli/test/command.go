@@ -9,12 +9,12 @@ import (
 )
 
 func Command(logger *slog.Logger) *cli.Command {
-	var recreate bool
+	var recreate, requireToken bool
 	return &cli.Command{
 		Name:      "test",
 		Aliases:   []string{"t"},
 		Usage:     "Test a package in Docker containers",
-		UsageText: "argd test [-r] [<package name>]",
+		UsageText: "argd test [-r] [--require-token] [<package name>]",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:        "recreate",
@@ -22,11 +22,17 @@ func Command(logger *slog.Logger) *cli.Command {
 				Usage:       "Recreate the containers",
 				Destination: &recreate,
 			},
+			&cli.BoolFlag{
+				Name:        "require-token",
+				Usage:       "Fail if no GitHub access token is found",
+				Destination: &requireToken,
+			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			return testpkg.Test(ctx, logger, &testpkg.Config{
-				PkgName:  cmd.Args().First(),
-				Recreate: recreate,
+				PkgName:      cmd.Args().First(),
+				Recreate:     recreate,
+				RequireToken: requireToken,
 			})
 		},
 	}
@@ -0,0 +1,47 @@
+package validate
+
+import (
+	"context"
+	"os"
+
+	"github.com/aquaproj/registry-tool/pkg/validate"
+	"github.com/urfave/cli/v3"
+)
+
+func Command() *cli.Command {
+	cfg := &validate.Config{
+		Stdout: os.Stdout,
+	}
+	return &cli.Command{
+		Name:      "validate",
+		Usage:     "Validate registry.yaml, pkg.yaml, and scaffold.yaml with JSON Schemas",
+		UsageText: "argd validate [--format text|json] [<file or directory> ...]",
+		Description: `Validate registry.yaml, pkg.yaml, and scaffold.yaml with JSON Schemas of aqua ` + validate.AquaVersion + `.
+
+JSON Schemas are embedded in argd, so no network access is needed.
+If no argument is given, files in the directory pkgs are validated.
+If directories are given, files in them are validated.
+Errors are output with the YAML paths and the positions in the format file:line:col.
+This command fails if any error is found.
+
+e.g.
+
+$ argd validate
+$ argd validate pkgs/cli/cli
+$ argd validate pkgs/cli/cli/registry.yaml pkgs/cli/cli/pkg.yaml
+$ argd validate --format json
+`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output format. text or json",
+				Value:       validate.FormatText,
+				Destination: &cfg.Format,
+			},
+		},
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg.Args = cmd.Args().Slice()
+			return validate.Run(cfg) //nolint:wrapcheck
+		},
+	}
+}
@@ -0,0 +1,236 @@
+// Package config reads the configuration file of argd.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	// DefaultPath is the path to the configuration file on the repository root.
+	DefaultPath = "argd.yaml"
+	// EnvPath is the environment variable to change the path to the configuration file.
+	EnvPath = "ARGD_CONFIG"
+
+	defaultBranchTemplate        = "feat/{{.PackageName}}"
+	defaultCommitMessageTemplate = "feat({{.PackageName}}): scaffold {{.PackageName}}"
+
+	// DefaultUpstreamURL is the URL of the upstream repository used by default.
+	DefaultUpstreamURL = "https://github.com/aquaproj/aqua-registry"
+	// UpstreamRemote is the remote used as the upstream repository if the URL of the upstream repository isn't configured.
+	UpstreamRemote = "upstream"
+
+	defaultUpstreamBranch = "main"
+
+	// DefaultGitHubBaseURL is the URL of GitHub.
+	DefaultGitHubBaseURL = "https://github.com"
+	// DefaultGitHubAPIBaseURL is the base URL of GitHub REST API.
+	DefaultGitHubAPIBaseURL = "https://api.github.com"
+
+	// pkgPlaceholder is a package name to find the position of the package name in rendered templates.
+	pkgPlaceholder = "\x00"
+)
+
+// Config is the configuration of argd.
+type Config struct {
+	Git              *Git              `yaml:"git"`
+	GitHub           *GitHub           `yaml:"github"`
+	GenerateRegistry *GenerateRegistry `yaml:"generate_registry"`
+}
+
+// GenerateRegistry is the configuration of files generated by argd gr in addition to registry.yaml.
+type GenerateRegistry struct {
+	// JSON is the path to registry.json. If it's empty, registry.json isn't generated.
+	JSON string `yaml:"json"`
+	// SearchIndex is the path to the search index. If it's empty, the search index isn't generated.
+	SearchIndex string `yaml:"search_index"`
+}
+
+// GitHub is the configuration of GitHub and GitHub REST API.
+type GitHub struct {
+	// BaseURL is the URL of GitHub. It's used to check if repositories are transferred.
+	BaseURL string `yaml:"base_url"`
+	// APIBaseURL is the base URL of GitHub REST API.
+	APIBaseURL string `yaml:"api_base_url"`
+	// CacheDir is the directory where responses of GitHub REST API are cached.
+	// If it's empty, <user cache directory>/argd/github is used.
+	CacheDir string `yaml:"cache_dir"`
+	// NoCache disables the cache of GitHub REST API.
+	NoCache bool `yaml:"no_cache"`
+}
+
+// Git is the configuration of branches and commits created by argd.
+type Git struct {
+	// BranchTemplate is a Go template of feature branches. .PackageName is available.
+	BranchTemplate string `yaml:"branch_template"`
+	// CommitMessageTemplate is a Go template of commit messages. .PackageName is available.
+	CommitMessageTemplate string `yaml:"commit_message_template"`
+	// SignOff adds a Signed-off-by trailer to commits.
+	SignOff bool `yaml:"sign_off"`
+	// Sign signs commits.
+	Sign bool `yaml:"sign"`
+	// SigningFormat is the format of signatures. openpgp, ssh, or x509.
+	// If it's empty, git's gpg.format is used.
+	SigningFormat string `yaml:"signing_format"`
+	// SigningKey is the key to sign commits.
+	// If it's empty, git's user.signingKey is used.
+	SigningKey string `yaml:"signing_key"`
+	// Upstream is the repository feature branches are created from.
+	Upstream *Upstream `yaml:"upstream"`
+}
+
+// Upstream is the upstream repository.
+type Upstream struct {
+	// URL is the URL of the upstream repository.
+	// If it's empty, the remote `upstream` is used if it exists.
+	// Otherwise DefaultUpstreamURL is used.
+	URL string `yaml:"url"`
+	// Branch is the default branch of the upstream repository.
+	Branch string `yaml:"branch"`
+}
+
+// Read reads the configuration file.
+// If the environment variable ARGD_CONFIG is set, the file is read.
+// Otherwise argd.yaml is read if it exists.
+// Default values are set to empty fields.
+func Read() (*Config, error) {
+	path := os.Getenv(EnvPath)
+	required := path != ""
+	if !required {
+		path = DefaultPath
+	}
+	cfg := &Config{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if required || !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("read the configuration file %s: %w", path, err)
+		}
+	} else if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parse the configuration file %s as YAML: %w", path, err)
+	}
+	if err := cfg.setDefault(); err != nil {
+		return nil, fmt.Errorf("validate the configuration file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) setDefault() error {
+	if c.Git == nil {
+		c.Git = &Git{}
+	}
+	if c.GitHub == nil {
+		c.GitHub = &GitHub{}
+	}
+	if c.GenerateRegistry == nil {
+		c.GenerateRegistry = &GenerateRegistry{}
+	}
+	c.GitHub.setDefault()
+	return c.Git.setDefault()
+}
+
+func (g *GitHub) setDefault() {
+	if g.BaseURL == "" {
+		g.BaseURL = DefaultGitHubBaseURL
+	}
+	if g.APIBaseURL == "" {
+		g.APIBaseURL = DefaultGitHubAPIBaseURL
+	}
+	if g.CacheDir == "" && !g.NoCache {
+		if dir, err := os.UserCacheDir(); err == nil {
+			g.CacheDir = filepath.Join(dir, "argd", "github")
+		}
+	}
+	if g.NoCache {
+		g.CacheDir = ""
+	}
+}
+
+func (g *Git) setDefault() error {
+	if g.BranchTemplate == "" {
+		g.BranchTemplate = defaultBranchTemplate
+	}
+	if g.CommitMessageTemplate == "" {
+		g.CommitMessageTemplate = defaultCommitMessageTemplate
+	}
+	if g.Upstream == nil {
+		g.Upstream = &Upstream{}
+	}
+	if g.Upstream.Branch == "" {
+		g.Upstream.Branch = defaultUpstreamBranch
+	}
+	switch g.SigningFormat {
+	case "", "openpgp", "ssh", "x509":
+	default:
+		return fmt.Errorf("signing_format must be openpgp, ssh, or x509: %s", g.SigningFormat)
+	}
+	branch, err := render(g.BranchTemplate, pkgPlaceholder)
+	if err != nil {
+		return fmt.Errorf("render branch_template: %w", err)
+	}
+	if strings.Count(branch, pkgPlaceholder) != 1 {
+		return errors.New("branch_template must include {{.PackageName}} only once")
+	}
+	return nil
+}
+
+func render(tpl, pkgName string) (string, error) {
+	t, err := template.New("_").Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return "", fmt.Errorf("parse a template: %w", err)
+	}
+	buf := &strings.Builder{}
+	if err := t.Execute(buf, map[string]string{
+		"PackageName": pkgName,
+	}); err != nil {
+		return "", fmt.Errorf("render a template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Branch returns the feature branch of the package.
+func (g *Git) Branch(pkgName string) (string, error) {
+	return render(g.BranchTemplate, pkgName)
+}
+
+// CommitMessage returns the commit message of the package.
+func (g *Git) CommitMessage(pkgName string) (string, error) {
+	return render(g.CommitMessageTemplate, pkgName)
+}
+
+// PackageNameFromBranch extracts the package name from the branch created with BranchTemplate.
+// It returns false if the branch doesn't match BranchTemplate.
+func (g *Git) PackageNameFromBranch(branch string) (string, bool, error) {
+	s, err := render(g.BranchTemplate, pkgPlaceholder)
+	if err != nil {
+		return "", false, err
+	}
+	prefix, suffix, _ := strings.Cut(s, pkgPlaceholder)
+	pkgName, ok := strings.CutPrefix(branch, prefix)
+	if !ok {
+		return "", false, nil
+	}
+	pkgName, ok = strings.CutSuffix(pkgName, suffix)
+	if !ok || pkgName == "" {
+		return "", false, nil
+	}
+	return pkgName, true, nil
+}
+
+// CommitOptions returns options of git commit with the message.
+func (g *Git) CommitOptions(msg string) *git.CommitOptions {
+	return &git.CommitOptions{
+		Message:       msg,
+		SignOff:       g.SignOff,
+		Sign:          g.Sign,
+		SigningFormat: g.SigningFormat,
+		SigningKey:    g.SigningKey,
+	}
+}
@@ -0,0 +1,126 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "argd.yaml")
+	if err := os.WriteFile(path, []byte(`git:
+  branch_template: "pkg-{{.PackageName}}-update"
+  sign_off: true
+github:
+  api_base_url: https://ghe.example.com/api/v3
+  no_cache: true
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, path)
+	cfg, err := config.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &config.Git{
+		BranchTemplate:        "pkg-{{.PackageName}}-update",
+		CommitMessageTemplate: "feat({{.PackageName}}): scaffold {{.PackageName}}",
+		SignOff:               true,
+		Upstream: &config.Upstream{
+			Branch: "main",
+		},
+	}
+	if diff := cmp.Diff(exp, cfg.Git); diff != "" {
+		t.Fatalf("git config (-want +got):\n%s", diff)
+	}
+	expGitHub := &config.GitHub{
+		BaseURL:    "https://github.com",
+		APIBaseURL: "https://ghe.example.com/api/v3",
+		NoCache:    true,
+	}
+	if diff := cmp.Diff(expGitHub, cfg.GitHub); diff != "" {
+		t.Fatalf("github config (-want +got):\n%s", diff)
+	}
+}
+
+func TestRead_InvalidBranchTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "argd.yaml")
+	if err := os.WriteFile(path, []byte(`git:
+  branch_template: "feat/new-package"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, path)
+	if _, err := config.Read(); err == nil {
+		t.Fatal("error should be returned")
+	}
+}
+
+func TestGit_PackageNameFromBranch(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name     string
+		template string
+		branch   string
+		exp      string
+		ok       bool
+	}{
+		{
+			name:     "prefix",
+			template: "feat/{{.PackageName}}",
+			branch:   "feat/cli/cli",
+			exp:      "cli/cli",
+			ok:       true,
+		},
+		{
+			name:     "prefix and suffix",
+			template: "registry/{{.PackageName}}/scaffold",
+			branch:   "registry/cli/cli/scaffold",
+			exp:      "cli/cli",
+			ok:       true,
+		},
+		{
+			name:     "not match",
+			template: "feat/{{.PackageName}}",
+			branch:   "main",
+		},
+		{
+			name:     "empty package name",
+			template: "feat/{{.PackageName}}",
+			branch:   "feat/",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			g := &config.Git{BranchTemplate: d.template}
+			pkgName, ok, err := g.PackageNameFromBranch(d.branch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != d.ok {
+				t.Fatalf("wanted %v, got %v", d.ok, ok)
+			}
+			if pkgName != d.exp {
+				t.Fatalf("wanted %s, got %s", d.exp, pkgName)
+			}
+		})
+	}
+}
+
+func TestGit_CommitOptions(t *testing.T) {
+	t.Parallel()
+	g := &config.Git{
+		SignOff:       true,
+		Sign:          true,
+		SigningFormat: "ssh",
+		SigningKey:    "~/.ssh/id_ed25519.pub",
+	}
+	exp := []string{"-c", "gpg.format=ssh", "commit", "-m", "feat: foo", "--signoff", "--gpg-sign=~/.ssh/id_ed25519.pub"}
+	if diff := cmp.Diff(exp, g.CommitOptions("feat: foo").Args()); diff != "" {
+		t.Fatalf("args (-want +got):\n%s", diff)
+	}
+}
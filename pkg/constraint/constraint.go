@@ -0,0 +1,55 @@
+// Package constraint evaluates version_constraint of packages in the same way as aqua.
+package constraint
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/aqua/v2/pkg/expr"
+)
+
+// TopLevel is the index meaning the version_constraint of the package.
+const TopLevel = -1
+
+// Match reports whether the version v matches the constraint.
+// If prefix isn't empty, v must have the prefix and semver is evaluated with the version without it.
+func Match(constraint, prefix, v string) bool {
+	sv := v
+	if prefix != "" {
+		var ok bool
+		sv, ok = strings.CutPrefix(v, prefix)
+		if !ok {
+			return false
+		}
+	}
+	a, err := expr.EvaluateVersionConstraints(slog.New(slog.DiscardHandler), constraint, v, sv)
+	return err == nil && a
+}
+
+// Prefix returns the version_prefix of the i-th version_overrides entry.
+// The version_prefix of the package is inherited if the entry doesn't set it.
+func Prefix(pkg *registry.PackageInfo, i int) string {
+	if i != TopLevel {
+		if prefix := pkg.VersionOverrides[i].VersionPrefix; prefix != nil {
+			return *prefix
+		}
+	}
+	return pkg.VersionPrefix
+}
+
+// Select returns the index of the entry selecting the version v.
+// It returns TopLevel if the version_constraint of the package matches v.
+// Otherwise the first version_overrides entry matching v is selected.
+// It returns false if no entry selects v.
+func Select(pkg *registry.PackageInfo, v string) (int, bool) {
+	if Match(pkg.VersionConstraints, pkg.VersionPrefix, v) {
+		return TopLevel, true
+	}
+	for i, vo := range pkg.VersionOverrides {
+		if Match(vo.VersionConstraints, Prefix(pkg, i), v) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,39 @@
+package constraint_test
+
+import (
+	"testing"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/constraint"
+)
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+	prefix := "cli/"
+	pkg := &registry.PackageInfo{
+		VersionConstraints: `semver(">= 3.0.0")`,
+		VersionOverrides: []*registry.VersionOverride{
+			{VersionConstraints: `semver("<= 1.0.0")`},
+			{VersionConstraints: `semver("< 3.0.0")`, VersionPrefix: &prefix},
+		},
+	}
+	data := []struct {
+		version string
+		idx     int
+		ok      bool
+	}{
+		{version: "v3.1.0", idx: constraint.TopLevel, ok: true},
+		{version: "v1.0.0", idx: 0, ok: true},
+		{version: "cli/v2.0.0", idx: 1, ok: true},
+		{version: "v2.0.0"},
+	}
+	for _, d := range data {
+		t.Run(d.version, func(t *testing.T) {
+			t.Parallel()
+			idx, ok := constraint.Select(pkg, d.version)
+			if idx != d.idx || ok != d.ok {
+				t.Fatalf("wanted (%d, %v), got (%d, %v)", d.idx, d.ok, idx, ok)
+			}
+		})
+	}
+}
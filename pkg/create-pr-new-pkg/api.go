@@ -1,7 +1,6 @@
 package newpkg
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
 	"errors"
@@ -13,8 +12,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/git"
 	"github.com/aquaproj/registry-tool/pkg/initcmd"
-	"github.com/aquaproj/registry-tool/pkg/osexec"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,11 +23,19 @@ var bodyTemplate []byte
 
 func CreatePRNewPkgs(ctx context.Context, logger *slog.Logger, pkgName string) error {
 	pkgName = strings.TrimPrefix(pkgName, "https://github.com/")
-	if err := checkDiffPackage(ctx, logger); err != nil {
+	repo := git.New(logger, "")
+	if err := checkDiffPackage(ctx, repo); err != nil {
 		return err
 	}
-	var err error
-	pkgName, err = getPkgFromBranch(ctx, logger, pkgName)
+	cfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("read the configuration file: %w", err)
+	}
+	branch, err := repo.CurrentBranch(ctx)
+	if err != nil {
+		return fmt.Errorf("get current branch: %w", err)
+	}
+	pkgName, err = getPkgFromBranch(cfg.Git, branch, pkgName)
 	if err != nil {
 		return err
 	}
@@ -44,8 +52,8 @@ func CreatePRNewPkgs(ctx context.Context, logger *slog.Logger, pkgName string) e
 	if err != nil {
 		return err
 	}
-	if err := command(ctx, "git", "add", "pkgs/"+pkgName, "registry.yaml"); err != nil {
-		return err
+	if err := repo.Add(ctx, "pkgs/"+pkgName, "registry.yaml"); err != nil {
+		return fmt.Errorf("stage changes: %w", err)
 	}
 	prBody := strings.Join([]string{
 		getBody(pkgName, desc),
@@ -56,20 +64,19 @@ func CreatePRNewPkgs(ctx context.Context, logger *slog.Logger, pkgName string) e
 		"",
 		string(bodyTemplate),
 	}, "\n")
-	branch := "feat/" + pkgName
 	if err := initcmd.Init(ctx); err != nil {
 		return err //nolint:wrapcheck
 	}
-	stderr := &bytes.Buffer{}
-	if err := commandStderr(ctx, io.MultiWriter(os.Stderr, stderr), "git", "push", "origin", branch); err != nil {
-		if strings.Contains(stderr.String(), "returned error: 403") {
+	if err := repo.Push(ctx, "origin", branch); err != nil {
+		gitErr := &git.Error{}
+		if errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "returned error: 403") {
 			logger.With(
 				"doc", "https://github.com/aquaproj/aqua-registry/blob/main/docs/troubleshooting.md",
 			).Warn(`you don't have the permission to push commits to the origin.
 Please fork aquaproj/aqua-registry and fix the origin url to your fork repository.
 For details, please see the document`)
 		} else {
-			return err
+			return fmt.Errorf("push the branch: %w", err)
 		}
 	}
 	if err := command(ctx, "aqua", "-c", "aqua/dev.yaml", "exec", "--", "gh", "pr", "create", "-w", "-t", "feat: add "+pkgName, "-b", prBody); err != nil {
@@ -104,47 +111,42 @@ func getBody(pkgName, desc string) string {
 	return fmt.Sprintf(`%s: %s`, pkgName, desc)
 }
 
-func checkDiffPackage(ctx context.Context, logger *slog.Logger) error {
-	cmd := exec.CommandContext(ctx, "git", "diff", "--quiet", "pkgs", "registry.yaml")
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
+func checkDiffPackage(ctx context.Context, repo git.Repo) error {
+	st, err := repo.Status(ctx, "pkgs", "registry.yaml")
+	if err != nil {
+		return fmt.Errorf("get the status of pkgs and registry.yaml: %w", err)
+	}
+	if len(st.Unstaged) > 0 {
 		return errors.New("there are unstaged changes in pkgs or registry.yaml")
 	}
-	cmd = exec.CommandContext(ctx, "git", "diff", "--cached", "--quiet", "pkgs", "registry.yaml")
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
+	if len(st.Staged) > 0 {
 		return errors.New("there are staged changes in pkgs or registry.yaml")
 	}
-	cmd = exec.CommandContext(ctx, "git", "ls-files", "--others", "--exclude-standard", "pkgs")
-	osexec.SetCancel(logger, cmd)
-	out, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("check untracked files in pkgs: %w", err)
-	}
-	if len(bytes.TrimSpace(out)) > 0 {
+	if len(st.Untracked) > 0 {
 		return errors.New("there are untracked files in pkgs")
 	}
 	return nil
 }
 
-func getPkgFromBranch(ctx context.Context, logger *slog.Logger, pkgName string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
-	osexec.SetCancel(logger, cmd)
-	out, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("get current branch: %w", err)
-	}
-	branch := strings.TrimSpace(string(out))
+func getPkgFromBranch(gitCfg *config.Git, branch, pkgName string) (string, error) {
 	if pkgName != "" {
-		if branch != "feat/"+pkgName {
-			return "", fmt.Errorf("branch %q doesn't match the package name %q (expected branch feat/%s)", branch, pkgName, pkgName)
+		expected, err := gitCfg.Branch(pkgName)
+		if err != nil {
+			return "", fmt.Errorf("get a branch name: %w", err)
+		}
+		if branch != expected {
+			return "", fmt.Errorf("branch %q doesn't match the package name %q (expected branch %s)", branch, pkgName, expected)
 		}
 		return pkgName, nil
 	}
-	if !strings.HasPrefix(branch, "feat/") {
-		return "", fmt.Errorf("branch %q doesn't have the prefix \"feat/\"", branch)
+	name, ok, err := gitCfg.PackageNameFromBranch(branch)
+	if err != nil {
+		return "", fmt.Errorf("get a package name from the branch: %w", err)
+	}
+	if !ok {
+		return "", fmt.Errorf("branch %q doesn't match the branch template %q", branch, gitCfg.BranchTemplate)
 	}
-	return strings.TrimPrefix(branch, "feat/"), nil
+	return name, nil
 }
 
 func command(ctx context.Context, cmdName string, args ...string) error {
@@ -159,16 +161,3 @@ func command(ctx context.Context, cmdName string, args ...string) error {
 	}
 	return nil
 }
-
-func commandStderr(ctx context.Context, stderr io.Writer, cmdName string, args ...string) error {
-	s := cmdName + " " + strings.Join(args, " ")
-	fmt.Fprintln(os.Stderr, "+ "+s)
-	cmd := exec.CommandContext(ctx, cmdName, args...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("execute a command: %s: %w", s, err)
-	}
-	return nil
-}
@@ -3,6 +3,8 @@ package newpkg
 import (
 	"strings"
 	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
 )
 
 func Test_getDesc(t *testing.T) {
@@ -87,3 +89,56 @@ func Test_getBody(t *testing.T) {
 		})
 	}
 }
+
+func Test_getPkgFromBranch(t *testing.T) {
+	t.Parallel()
+	gitCfg := &config.Git{BranchTemplate: "registry/{{.PackageName}}"}
+	data := []struct {
+		name    string
+		branch  string
+		pkgName string
+		exp     string
+		isErr   bool
+	}{
+		{
+			name:   "from branch",
+			branch: "registry/cli/cli",
+			exp:    "cli/cli",
+		},
+		{
+			name:    "match",
+			branch:  "registry/cli/cli",
+			pkgName: "cli/cli",
+			exp:     "cli/cli",
+		},
+		{
+			name:    "mismatch",
+			branch:  "registry/cli/cli",
+			pkgName: "suzuki-shunsuke/tfcmt",
+			isErr:   true,
+		},
+		{
+			name:   "not match template",
+			branch: "feat/cli/cli",
+			isErr:  true,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			pkgName, err := getPkgFromBranch(gitCfg, d.branch, d.pkgName)
+			if err != nil {
+				if d.isErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if d.isErr {
+				t.Fatal("error should be returned")
+			}
+			if pkgName != d.exp {
+				t.Fatalf("wanted %s, got %s", d.exp, pkgName)
+			}
+		})
+	}
+}
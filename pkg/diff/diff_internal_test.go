@@ -0,0 +1,56 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name string
+		old  string
+		new  string
+		exp  string
+	}{
+		{
+			name: "same",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+		},
+		{
+			name: "changed",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			exp: `diff old new
+--- old
++++ new
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`,
+		},
+		{
+			name: "no newline",
+			old:  "a",
+			new:  "a\n",
+			exp: `diff old new
+--- old
++++ new
+@@ -1,1 +1,1 @@
+-a
+\ No newline at end of file
++a
+`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			if got := string(Diff("old", []byte(d.old), "new", []byte(d.new))); got != d.exp {
+				t.Fatalf("wanted\n%s\ngot\n%s", d.exp, got)
+			}
+		})
+	}
+}
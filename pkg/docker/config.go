@@ -21,6 +21,14 @@ const (
 	FilePermission os.FileMode = 0o644
 )
 
+// DockerfileName returns the file name under docker/ used to build the image.
+func (c Config) DockerfileName() string {
+	if c.Dockerfile == "" {
+		return "Dockerfile"
+	}
+	return c.Dockerfile
+}
+
 // DefaultLinuxContainer returns the default Linux container configuration.
 func DefaultLinuxContainer() Config {
 	return Config{
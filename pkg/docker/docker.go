@@ -295,15 +295,8 @@ func (dm *Manager) imageExists(ctx context.Context, logger *slog.Logger) bool {
 	return cmd.Run() == nil
 }
 
-func (dm *Manager) dockerfileName() string {
-	if dm.config.Dockerfile == "" {
-		return "Dockerfile"
-	}
-	return dm.config.Dockerfile
-}
-
 func (dm *Manager) dockerfileNotChanged() (bool, error) {
-	name := dm.dockerfileName()
+	name := dm.config.DockerfileName()
 	srcPath := filepath.Join("docker", name)
 	cachePath := filepath.Join(".build", name)
 
@@ -328,7 +321,7 @@ func (dm *Manager) buildImage(ctx context.Context, logger *slog.Logger) error {
 		return fmt.Errorf("copy aqua-policy.yaml: %w", err)
 	}
 
-	name := dm.dockerfileName()
+	name := dm.config.DockerfileName()
 	src := filepath.Join("docker", name)
 
 	cmd := exec.CommandContext(ctx, "docker", "build", "-t", dm.config.Image, "-f", src, "docker") //nolint:gosec
@@ -0,0 +1,240 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aquaproj/registry-tool/pkg/docker"
+	"github.com/aquaproj/registry-tool/pkg/semver"
+)
+
+const (
+	minAquaVersion = "2.43.0"
+	minGHVersion   = "2.0.0"
+	// minFreeSpace is the free disk space required to build container images and install packages.
+	minFreeSpace uint64 = 5 << 30
+	devYAML             = "aqua/dev.yaml"
+)
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+\.\d+\S*`)
+
+func (d *Doctor) path(p string) string {
+	return filepath.Join(d.dir, filepath.FromSlash(p))
+}
+
+func (d *Doctor) checkContainerEngine(ctx context.Context) *Result {
+	r := &Result{Name: "container engine"}
+	out, err := d.runner.Output(ctx, "docker", "version")
+	if out == "" && err != nil {
+		r.Status = StatusError
+		r.Message = "docker isn't found"
+		r.Hint = "Install Docker or Podman. If you use Podman, make the command `docker` available (e.g. install podman-docker)"
+		return r
+	}
+	engine := "Docker"
+	if strings.Contains(out, "Podman") {
+		engine = "Podman"
+	}
+	ver, err := d.runner.Output(ctx, "docker", "version", "--format", "{{.Client.Version}}")
+	if err != nil || ver == "" {
+		ver = "unknown version"
+	}
+	r.Status = StatusOK
+	r.Message = engine + " " + ver
+	return r
+}
+
+func (d *Doctor) checkContainerDaemon(ctx context.Context) *Result {
+	r := &Result{Name: "container daemon"}
+	ver, err := d.runner.Output(ctx, "docker", "version", "--format", "{{.Server.Version}}")
+	if err != nil {
+		r.Status = StatusError
+		r.Message = "the container daemon isn't reachable"
+		r.Hint = "Start Docker (or run `podman machine start`), and make sure `docker version` succeeds"
+		return r
+	}
+	r.Status = StatusOK
+	r.Message = "reachable (server version " + ver + ")"
+	return r
+}
+
+// checkVersion checks if the output of a command includes a version newer than or equal to minVersion.
+func checkVersion(r *Result, out, minVersion, updateHint string) *Result {
+	ver := versionPattern.FindString(out)
+	if ver == "" {
+		r.Status = StatusWarn
+		r.Message = "failed to parse the version: " + out
+		return r
+	}
+	if semver.GreaterThan(minVersion, ver) {
+		r.Status = StatusError
+		r.Message = fmt.Sprintf("%s is older than the minimum version %s", ver, minVersion)
+		r.Hint = updateHint
+		return r
+	}
+	r.Status = StatusOK
+	r.Message = ver
+	return r
+}
+
+func (d *Doctor) checkAqua(ctx context.Context) *Result {
+	r := &Result{Name: "aqua"}
+	out, err := d.runner.Output(ctx, "aqua", "version")
+	if err != nil {
+		r.Status = StatusError
+		r.Message = "aqua isn't found"
+		r.Hint = "Install aqua: https://aquaproj.github.io/docs/install"
+		return r
+	}
+	return checkVersion(r, out, minAquaVersion, "Update aqua: `aqua update-aqua`")
+}
+
+func (d *Doctor) checkDevYAML(context.Context) *Result {
+	r := &Result{Name: devYAML}
+	if _, err := os.Stat(d.path(devYAML)); err != nil {
+		r.Status = StatusError
+		r.Message = devYAML + " isn't found"
+		r.Hint = "Run argd at the root directory of aqua-registry"
+		return r
+	}
+	r.Status = StatusOK
+	r.Message = "found"
+	return r
+}
+
+func (d *Doctor) checkGH(ctx context.Context) *Result {
+	r := &Result{Name: "gh"}
+	out, err := d.runner.Output(ctx, "aqua", "-c", d.path(devYAML), "exec", "--", "gh", "--version")
+	if err != nil {
+		r.Status = StatusError
+		r.Message = "gh isn't available"
+		r.Hint = "Install gh: `aqua -c " + devYAML + " i`"
+		return r
+	}
+	return checkVersion(r, out, minGHVersion, "Update gh in "+devYAML)
+}
+
+// normalizeRepoURL converts a URL of a git repository to "<host>/<owner>/<repo>".
+func normalizeRepoURL(u string) string {
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	if i := strings.Index(u, "://"); i != -1 {
+		u = u[i+3:]
+		if j := strings.Index(u, "@"); j != -1 {
+			u = u[j+1:]
+		}
+	} else if host, path, ok := strings.Cut(u, ":"); ok {
+		// scp-like syntax: git@github.com:owner/repo
+		if j := strings.Index(host, "@"); j != -1 {
+			host = host[j+1:]
+		}
+		u = host + "/" + path
+	}
+	return strings.ToLower(u)
+}
+
+func (d *Doctor) checkOrigin(ctx context.Context) *Result {
+	r := &Result{Name: "origin"}
+	u, err := d.repo.RemoteURL(ctx, "origin")
+	if err != nil {
+		r.Status = StatusError
+		r.Message = "the remote origin isn't found"
+		r.Hint = "Fork aquaproj/aqua-registry and add the fork as the remote origin"
+		return r
+	}
+	if normalizeRepoURL(u) == normalizeRepoURL(d.upstreamURL) {
+		r.Status = StatusWarn
+		r.Message = "origin points at the upstream repository " + u
+		r.Hint = "Fork the repository and set origin to your fork: `git remote set-url origin <fork URL>`"
+		return r
+	}
+	r.Status = StatusOK
+	r.Message = u
+	return r
+}
+
+func (d *Doctor) checkToken(ctx context.Context) *Result {
+	r := &Result{Name: "GitHub access token"}
+	token, err := d.resolveToken(ctx)
+	if err != nil {
+		r.Status = StatusError
+		r.Message = err.Error()
+		r.Hint = "Fix the configuration of ghtkn, run `gh auth login`, set the token in the keyring by `aqua token set`, or set the environment variable GITHUB_TOKEN"
+		return r
+	}
+	if token == nil || token.Value == "" {
+		r.Status = StatusWarn
+		r.Message = "no token is found, so GitHub API rate limits are strict"
+		r.Hint = "Set the environment variable GITHUB_TOKEN or AQUA_GITHUB_TOKEN, enable ghtkn by AQUA_GHTKN_ENABLED=true, log in by `gh auth login`, or set the token by `aqua token set` and enable the keyring by AQUA_KEYRING_ENABLED=true"
+		return r
+	}
+	r.Status = StatusOK
+	r.Message = "found (source: " + token.Source + ")"
+	return r
+}
+
+func (d *Doctor) checkDiskSpace(context.Context) *Result {
+	r := &Result{Name: "disk space"}
+	dir := d.dir
+	if dir == "" {
+		dir = "."
+	}
+	free, err := d.freeSpace(dir)
+	if err != nil {
+		r.Status = StatusWarn
+		r.Message = "failed to get free disk space: " + err.Error()
+		return r
+	}
+	if free < minFreeSpace {
+		r.Status = StatusWarn
+		r.Message = fmt.Sprintf("%s is free, which is less than %s", formatBytes(free), formatBytes(minFreeSpace))
+		r.Hint = "Free up disk space. e.g. `docker system prune`"
+		return r
+	}
+	r.Status = StatusOK
+	r.Message = formatBytes(free) + " free"
+	return r
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1 << 10
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func (d *Doctor) checkDockerfiles(context.Context) *Result {
+	r := &Result{Name: "Dockerfiles"}
+	var missing []string
+	for _, c := range []docker.Config{docker.DefaultLinuxContainer(), docker.DefaultAlpineContainer()} {
+		p := "docker/" + c.DockerfileName()
+		if _, err := os.Stat(d.path(p)); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				r.Status = StatusError
+				r.Message = fmt.Sprintf("failed to check %s: %v", p, err)
+				return r
+			}
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		r.Status = StatusError
+		r.Message = "not found: " + strings.Join(missing, ", ")
+		r.Hint = "Run argd at the root directory of aqua-registry, and pull the latest main branch"
+		return r
+	}
+	r.Status = StatusOK
+	r.Message = "found"
+	return r
+}
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package doctor
+
+import "errors"
+
+func freeSpace(string) (uint64, error) {
+	return 0, errors.New("getting free disk space isn't supported on this platform")
+}
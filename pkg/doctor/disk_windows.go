@@ -0,0 +1,21 @@
+//go:build windows
+
+package doctor
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+func freeSpace(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, fmt.Errorf("convert the path to UTF-16: %w", err)
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, &totalFree); err != nil {
+		return 0, fmt.Errorf("get free disk space: %w", err)
+	}
+	return free, nil
+}
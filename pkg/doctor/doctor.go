@@ -0,0 +1,142 @@
+// Package doctor diagnoses the environment to develop aqua Registry.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/osexec"
+)
+
+// Status is the result of a check.
+type Status string
+
+const (
+	StatusOK    Status = "ok"
+	StatusWarn  Status = "warn"
+	StatusError Status = "error"
+)
+
+// Result is the result of a check.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	// Hint is how to fix the problem.
+	Hint string `json:"hint,omitempty"`
+}
+
+// errCheckFailed is returned when some checks fail.
+var errCheckFailed = errors.New("some checks failed")
+
+type commandRunner interface {
+	// Output runs a command and returns the standard output.
+	Output(ctx context.Context, name string, args ...string) (string, error)
+}
+
+type execRunner struct {
+	logger *slog.Logger
+}
+
+func (r *execRunner) Output(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	osexec.SetCancel(r.logger, cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", cmd.String(), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Doctor checks the environment.
+type Doctor struct {
+	runner       commandRunner
+	repo         git.Repo
+	resolveToken func(ctx context.Context) (*github.Token, error)
+	freeSpace    func(path string) (uint64, error)
+	// dir is the root directory of aqua-registry. An empty string means the current directory.
+	dir string
+	// upstreamURL is the URL of the upstream repository.
+	upstreamURL string
+}
+
+// New returns a Doctor checking the environment in the current directory.
+func New(logger *slog.Logger, gitCfg *config.Git) *Doctor {
+	upstreamURL := gitCfg.Upstream.URL
+	if upstreamURL == "" {
+		upstreamURL = config.DefaultUpstreamURL
+	}
+	return &Doctor{
+		runner: &execRunner{logger: logger},
+		repo:   git.New(logger, ""),
+		resolveToken: func(ctx context.Context) (*github.Token, error) {
+			return github.ResolveAccessToken(ctx, logger)
+		},
+		freeSpace:   freeSpace,
+		upstreamURL: upstreamURL,
+	}
+}
+
+// Run runs all checks.
+func (d *Doctor) Run(ctx context.Context) []*Result {
+	checks := []func(ctx context.Context) *Result{
+		d.checkContainerEngine,
+		d.checkContainerDaemon,
+		d.checkAqua,
+		d.checkDevYAML,
+		d.checkGH,
+		d.checkOrigin,
+		d.checkToken,
+		d.checkDiskSpace,
+		d.checkDockerfiles,
+	}
+	results := make([]*Result, len(checks))
+	for i, check := range checks {
+		results[i] = check(ctx)
+	}
+	return results
+}
+
+// Print writes results in the text format.
+// It returns an error if any check fails.
+func Print(w io.Writer, results []*Result) error {
+	failed := false
+	for _, r := range results {
+		fmt.Fprintf(w, "[%s] %s: %s\n", strings.ToUpper(string(r.Status)), r.Name, r.Message)
+		if r.Hint != "" {
+			fmt.Fprintf(w, "    hint: %s\n", r.Hint)
+		}
+		if r.Status == StatusError {
+			failed = true
+		}
+	}
+	if failed {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// PrintJSON writes results as JSON.
+// It returns an error if any check fails.
+func PrintJSON(w io.Writer, results []*Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return fmt.Errorf("encode results as JSON: %w", err)
+	}
+	for _, r := range results {
+		if r.Status == StatusError {
+			return errCheckFailed
+		}
+	}
+	return nil
+}
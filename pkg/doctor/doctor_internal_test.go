@@ -0,0 +1,146 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeRunner struct {
+	outputs map[string]string
+}
+
+func (r *fakeRunner) Output(_ context.Context, name string, args ...string) (string, error) {
+	out, ok := r.outputs[strings.Join(append([]string{name}, args...), " ")]
+	if !ok {
+		return "", errors.New("command failed")
+	}
+	return out, nil
+}
+
+type fakeRepo struct {
+	git.Repo
+
+	origin string
+}
+
+func (r *fakeRepo) RemoteURL(context.Context, string) (string, error) {
+	if r.origin == "" {
+		return "", errors.New("no such remote")
+	}
+	return r.origin, nil
+}
+
+func newTestDoctor(t *testing.T, origin string, outputs map[string]string, files ...string) *Doctor {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil { //nolint:gosec
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil { //nolint:gosec
+			t.Fatal(err)
+		}
+	}
+	return &Doctor{
+		runner: &fakeRunner{outputs: outputs},
+		repo:   &fakeRepo{origin: origin},
+		resolveToken: func(context.Context) (*github.Token, error) {
+			return &github.Token{Value: "xxx", Source: github.SourceGitHubToken}, nil
+		},
+		freeSpace: func(string) (uint64, error) {
+			return 10 << 30, nil
+		},
+		dir:         dir,
+		upstreamURL: "https://github.com/aquaproj/aqua-registry",
+	}
+}
+
+func TestDoctor_Run(t *testing.T) { //nolint:funlen
+	t.Parallel()
+	healthy := map[string]string{
+		"docker version": "Client: Podman Engine\nVersion: 5.2.0",
+		"docker version --format {{.Client.Version}}": "5.2.0",
+		"docker version --format {{.Server.Version}}": "5.2.0",
+		"aqua version": "aqua version 2.53.0 (abc)",
+	}
+	d := newTestDoctor(t, "git@github.com:suzuki-shunsuke/aqua-registry.git", healthy,
+		"aqua/dev.yaml", "docker/Dockerfile", "docker/Dockerfile-alpine")
+	healthy["aqua -c "+filepath.Join(d.dir, "aqua", "dev.yaml")+" exec -- gh --version"] = "gh version 2.60.0 (2024-10-01)"
+	exp := []*Result{
+		{Name: "container engine", Status: StatusOK, Message: "Podman 5.2.0"},
+		{Name: "container daemon", Status: StatusOK, Message: "reachable (server version 5.2.0)"},
+		{Name: "aqua", Status: StatusOK, Message: "2.53.0"},
+		{Name: "aqua/dev.yaml", Status: StatusOK, Message: "found"},
+		{Name: "gh", Status: StatusOK, Message: "2.60.0"},
+		{Name: "origin", Status: StatusOK, Message: "git@github.com:suzuki-shunsuke/aqua-registry.git"},
+		{Name: "GitHub access token", Status: StatusOK, Message: "found (source: GITHUB_TOKEN)"},
+		{Name: "disk space", Status: StatusOK, Message: "10.0 GiB free"},
+		{Name: "Dockerfiles", Status: StatusOK, Message: "found"},
+	}
+	results := d.Run(t.Context())
+	if diff := cmp.Diff(exp, results); diff != "" {
+		t.Fatalf("results (-want +got):\n%s", diff)
+	}
+	if err := Print(&bytes.Buffer{}, results); err != nil {
+		t.Fatal(err)
+	}
+
+	d = newTestDoctor(t, "https://github.com/aquaproj/aqua-registry.git", map[string]string{
+		"docker version": "Client: Docker Engine - Community\n Version: 27.1.1",
+		"docker version --format {{.Client.Version}}": "27.1.1",
+		"aqua version": "aqua version 2.10.0",
+	}, "docker/Dockerfile")
+	d.resolveToken = func(context.Context) (*github.Token, error) {
+		return nil, nil //nolint:nilnil
+	}
+	d.freeSpace = func(string) (uint64, error) {
+		return 1 << 30, nil
+	}
+	statuses := map[string]Status{}
+	results = d.Run(t.Context())
+	for _, r := range results {
+		statuses[r.Name] = r.Status
+	}
+	expStatuses := map[string]Status{
+		"container engine":    StatusOK,
+		"container daemon":    StatusError,
+		"aqua":                StatusError,
+		"aqua/dev.yaml":       StatusError,
+		"gh":                  StatusError,
+		"origin":              StatusWarn,
+		"GitHub access token": StatusWarn,
+		"disk space":          StatusWarn,
+		"Dockerfiles":         StatusError,
+	}
+	if diff := cmp.Diff(expStatuses, statuses); diff != "" {
+		t.Fatalf("statuses (-want +got):\n%s", diff)
+	}
+	if err := PrintJSON(&bytes.Buffer{}, results); !errors.Is(err, errCheckFailed) {
+		t.Fatalf("wanted errCheckFailed, got %v", err)
+	}
+}
+
+func TestNormalizeRepoURL(t *testing.T) {
+	t.Parallel()
+	for _, u := range []string{
+		"https://github.com/aquaproj/aqua-registry",
+		"https://github.com/aquaproj/aqua-registry.git",
+		"https://x-access-token@github.com/aquaproj/aqua-registry/",
+		"git@github.com:aquaproj/aqua-registry.git",
+		"ssh://git@github.com/AquaProj/aqua-registry",
+	} {
+		if s := normalizeRepoURL(u); s != "github.com/aquaproj/aqua-registry" {
+			t.Fatalf("%s: got %s", u, s)
+		}
+	}
+}
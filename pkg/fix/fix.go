@@ -1,194 +1,272 @@
 package fix
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/aquaproj/aqua/v2/pkg/config/registry"
-	"github.com/aquaproj/registry-tool/pkg/naming"
-	"github.com/goccy/go-yaml"
-	"github.com/goccy/go-yaml/parser"
-	"github.com/suzuki-shunsuke/go-yamledit/yamledit"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/lint"
+	"github.com/aquaproj/registry-tool/pkg/pkgfile"
 	"github.com/suzuki-shunsuke/slog-error/slogerr"
 )
 
-func Fix(ctx context.Context, logger *slog.Logger, args []string) error {
-	for _, arg := range args {
-		if err := fix(ctx, logger, arg); err != nil {
-			return slogerr.With(err, "arg", arg) //nolint:wrapcheck
-		}
+// Options is options of Fix.
+type Options struct {
+	// All fixes all pkgs/**/registry.yaml.
+	All bool
+	// ChangedSince fixes pkgs/**/registry.yaml changed from the revision and untracked.
+	ChangedSince string
+	// Check doesn't write files, and Fix returns an error if any file would be changed.
+	Check bool
+	// Workers is the number of files fixed concurrently. If it's 0, runtime.GOMAXPROCS(0) is used.
+	Workers int
+	// Stdout is the writer paths to changed files are output to.
+	Stdout io.Writer
+	// Repo is used to find changed files with ChangedSince.
+	Repo git.Repo
+}
+
+var errWouldChange = errors.New("files would be changed by argd fix")
+
+// target is a registry.yaml to be fixed.
+type target struct {
+	PkgName string
+	Path    string
+}
+
+// Fix fixes registry.yaml of packages and outputs paths to changed files.
+func Fix(ctx context.Context, logger *slog.Logger, opts *Options, args []string) error {
+	targets, err := listTargets(ctx, logger, opts, args)
+	if err != nil {
+		return err
+	}
+	changed, err := fixFiles(ctx, logger, targets, opts)
+	for _, p := range changed {
+		fmt.Fprintln(opts.Stdout, p)
+	}
+	if err != nil {
+		return err
+	}
+	if opts.Check && len(changed) > 0 {
+		return slogerr.With(errWouldChange, "num_of_files", len(changed)) //nolint:wrapcheck
+	}
+	if len(changed) > 0 {
+		logger.Info("fixed files", "num_of_files", len(changed))
 	}
 	return nil
 }
 
-func fix(ctx context.Context, logger *slog.Logger, arg string) error {
-	base := filepath.Base(arg)
-	switch base {
-	case "registry.yaml":
-		dir := filepath.ToSlash(filepath.Dir(arg))
-		pkgName, ok := strings.CutPrefix(dir, "pkgs/")
-		if !ok {
-			return nil
+func listTargets(ctx context.Context, logger *slog.Logger, opts *Options, args []string) ([]*target, error) {
+	if (opts.All || opts.ChangedSince != "") && len(args) > 0 {
+		return nil, errors.New("arguments can't be passed with --all and --changed-since")
+	}
+	if opts.All && opts.ChangedSince != "" {
+		return nil, errors.New("--all and --changed-since can't be used at the same time")
+	}
+	switch {
+	case opts.All:
+		return findRegistryFiles()
+	case opts.ChangedSince != "":
+		return changedRegistryFiles(ctx, opts.Repo, opts.ChangedSince)
+	}
+	var targets []*target
+	for _, arg := range args {
+		t, err := argTarget(ctx, logger, arg)
+		if err != nil {
+			return nil, slogerr.With(err, "arg", arg) //nolint:wrapcheck
 		}
-		if err := fixRegistryYAML(pkgName, arg); err != nil {
-			return err
+		if t != nil {
+			targets = append(targets, t)
 		}
-	case "pkg.yaml":
-		return nil
-	case "scaffold.yaml":
-		return nil
+	}
+	return targets, nil
+}
+
+// argTarget converts an argument to a target.
+// It returns nil if the argument isn't a file to be fixed.
+func argTarget(ctx context.Context, logger *slog.Logger, arg string) (*target, error) {
+	switch filepath.Base(arg) {
+	case "registry.yaml":
+		return registryTarget(arg), nil
+	case "pkg.yaml", "scaffold.yaml":
+		return nil, nil
 	default:
-		if err := fixPackage(ctx, logger, arg); err != nil {
-			return err
+		p, err := pkgfile.Resolve(ctx, logger, arg)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
 		}
+		return registryTarget(p), nil
 	}
-	return nil
 }
 
-func fixPackage(ctx context.Context, logger *slog.Logger, pkgName string) error {
-	pkgName, err := naming.Resolve(ctx, logger, pkgName)
-	if err != nil {
-		return fmt.Errorf("resolve package name: %w", err)
+// registryTarget returns nil if the registry.yaml isn't in the directory pkgs.
+func registryTarget(p string) *target {
+	dir := filepath.ToSlash(filepath.Dir(p))
+	pkgName, ok := strings.CutPrefix(dir, "pkgs/")
+	if !ok {
+		return nil
+	}
+	return &target{
+		PkgName: pkgName,
+		Path:    p,
 	}
-
-	pkgDir := filepath.Join(append([]string{"pkgs"}, strings.Split(pkgName, "/")...)...)
-
-	registryFile := filepath.Join(pkgDir, "registry.yaml")
-	return fixRegistryYAML(pkgName, registryFile)
 }
 
-func fixRegistryYAML(pkgName, registryFile string) error {
-	rb, err := os.ReadFile(registryFile)
+func findRegistryFiles() ([]*target, error) {
+	paths, err := pkgfile.Find(pkgfile.RegistryFile)
 	if err != nil {
-		return fmt.Errorf("read %s: %w", registryFile, err)
+		return nil, err //nolint:wrapcheck
 	}
+	targets := make([]*target, len(paths))
+	for i, p := range paths {
+		targets[i] = registryTarget(p)
+	}
+	return targets, nil
+}
 
-	file, err := parser.ParseBytes(rb, parser.ParseComments)
+// changedRegistryFiles returns pkgs/**/registry.yaml changed from the revision and untracked.
+// Deleted files are ignored.
+func changedRegistryFiles(ctx context.Context, repo git.Repo, rev string) ([]*target, error) {
+	changed, err := repo.DiffNames(ctx, rev, "pkgs")
 	if err != nil {
-		return fmt.Errorf("parse registry YAML: %w", err)
+		return nil, fmt.Errorf("list files changed from %s: %w", rev, err)
 	}
-	body := file.Docs[0].Body
-
-	var registryCfg registry.Config
-	if err := yaml.Unmarshal(rb, &registryCfg); err != nil {
-		return fmt.Errorf("parse %s: %w", registryFile, err)
+	status, err := repo.Status(ctx, "pkgs")
+	if err != nil {
+		return nil, fmt.Errorf("get the status of pkgs: %w", err)
 	}
+	paths := slices.Concat(changed, status.Untracked)
+	slices.Sort(paths)
+	var targets []*target
+	for _, p := range slices.Compact(paths) {
+		if filepath.Base(p) != "registry.yaml" {
+			continue
+		}
+		if _, err := os.Stat(p); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("stat %s: %w", p, err)
+		}
+		if t := registryTarget(p); t != nil {
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
 
-	if len(registryCfg.PackageInfos) == 0 {
-		return slogerr.With(errors.New("packages is empty"), "file", registryFile) //nolint:wrapcheck
+// fixFiles fixes files concurrently and returns paths to changed files in the order of targets.
+// Even if some files fail, other files are fixed and all errors are returned.
+func fixFiles(ctx context.Context, logger *slog.Logger, targets []*target, opts *Options) ([]string, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
 	}
-	if len(registryCfg.PackageInfos) > 1 {
-		return slogerr.With(errors.New("packages must include only one package"), "file", registryFile) //nolint:wrapcheck
+	changed := make([]bool, len(targets))
+	errs := make([]error, len(targets))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(targets)) {
+		wg.Go(func() {
+			for i := range indices {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				t := targets[i]
+				c, err := fixRegistryYAML(logger, t.PkgName, t.Path, opts.Check)
+				if err != nil {
+					errs[i] = slogerr.With(err, "file", t.Path)
+					continue
+				}
+				changed[i] = c
+			}
+		})
 	}
-	pkgInfo := registryCfg.PackageInfos[0]
-	actions, err := getActionsForPkgInfo(pkgName, pkgInfo)
-	if err != nil {
-		return err
+	for i := range targets {
+		indices <- i
 	}
-	for _, act := range actions {
-		if err := act.Run(body); err != nil {
-			return fmt.Errorf("run fix action: %w", err)
+	close(indices)
+	wg.Wait()
+	var paths []string
+	for i, t := range targets {
+		if changed[i] {
+			paths = append(paths, filepath.ToSlash(t.Path))
 		}
 	}
-	return writeFile(registryFile, []byte(file.String()))
+	return paths, errors.Join(errs...)
 }
 
-func writeFile(path string, data []byte) error {
-	stat, err := os.Stat(path)
-	if err != nil {
-		return fmt.Errorf("stat %s: %w", path, err)
-	}
-	if err := os.WriteFile(filepath.Clean(path), data, stat.Mode()); err != nil { //nolint:gosec
-		return fmt.Errorf("write %s: %w", path, err)
+// fixRules returns lint rules whose fixes argd fix applies.
+// Only rules whose fixes are proven to keep the behavior of aqua are applied to the whole registry.
+// Add a rule after confirming that.
+func fixRules() []lint.Rule {
+	ids := []string{
+		"exe-suffix",
+		"redundant-name",
+		"redundant-override",
+		"identity-replacement",
+		"redundant-supported-envs",
+		"redundant-files",
 	}
-	return nil
+	return slices.DeleteFunc(lint.Rules(), func(rule lint.Rule) bool {
+		return !slices.Contains(ids, rule.ID())
+	})
 }
 
-func getActionsForPkgInfo(pkgName string, pkgInfo *registry.PackageInfo) ([]yamledit.Action, error) {
-	actions := fixFiles(pkgInfo)
-	if strings.Contains(pkgName, ".") {
-		return actions, nil
+// fixRegistryYAML fixes the file and returns true if the file is changed.
+// If check is true, the file isn't written.
+// pkg.yaml isn't needed to fix the file, so an invalid pkg.yaml is reported without failing.
+func fixRegistryYAML(logger *slog.Logger, pkgName, registryFile string, check bool) (bool, error) {
+	b, err := os.ReadFile(registryFile)
+	if err != nil {
+		return false, fmt.Errorf("read %s: %w", registryFile, err)
 	}
-
-	repoFullName := pkgInfo.RepoOwner + "/" + pkgInfo.RepoName
-	if pkgInfo.RepoOwner == "" {
-		return nil, errors.New("repo_owner must be specified if package name doesn't include period")
+	f, err := lint.LoadRegistry(registryFile)
+	if err != nil {
+		return false, err //nolint:wrapcheck
 	}
-	if pkgInfo.RepoName == "" {
-		return nil, errors.New("if package name doesn't include period, repo_name must be specified")
+	f.PkgName = pkgName
+	if err := f.ReadPkgYAML(); err != nil {
+		slogerr.WithError(logger, err).Warn("pkg.yaml is invalid", "file", registryFile)
 	}
-	if pkgInfo.Name == repoFullName {
-		actions = append(actions, yamledit.MapAction(
-			"$.packages[0]",
-			yamledit.RemoveKeys("name"),
-		))
+	if err := validatePackage(pkgName, f.Package); err != nil {
+		return false, err
 	}
-
-	return actions, nil
-}
-
-func fixFiles(pkgInfo *registry.PackageInfo) []yamledit.Action {
-	var actions []yamledit.Action
-	for i, file := range pkgInfo.Files {
-		actions = append(
-			actions,
-			yamledit.MapAction(
-				fmt.Sprintf("$.packages[0].files[%d]", i),
-				fixFile(file)...))
-	}
-	for i, ov := range pkgInfo.Overrides {
-		for j, file := range ov.Files {
-			actions = append(
-				actions,
-				yamledit.MapAction(
-					fmt.Sprintf("$.packages[0].overrides[%d].files[%d]", i, j),
-					fixFile(file)...))
-		}
+	if _, err := lint.Fix(f, fixRules()); err != nil {
+		return false, fmt.Errorf("fix %s: %w", registryFile, err)
 	}
-	for i, vov := range pkgInfo.VersionOverrides {
-		for j, file := range vov.Files {
-			actions = append(
-				actions,
-				yamledit.MapAction(
-					fmt.Sprintf("$.packages[0].version_overrides[%d].files[%d]", i, j),
-					fixFile(file)...))
-		}
-		for j, ov := range vov.Overrides {
-			for k, file := range ov.Files {
-				actions = append(
-					actions,
-					yamledit.MapAction(
-						fmt.Sprintf("$.packages[0].version_overrides[%d].overrides[%d].files[%d]", i, j, k),
-						fixFile(file)...))
-			}
-		}
+	data := []byte(f.AST.String())
+	if bytes.Equal(b, data) {
+		return false, nil
+	}
+	if check {
+		return true, nil
 	}
-	return actions
+	return true, pkgfile.Write(registryFile, data) //nolint:wrapcheck
 }
 
-// .files
-// .overrides[].files
-// .version_overrides[].files
-// .version_overrides[].overrides[].files
-// .name, .src
-
-func fixFile(file *registry.File) []yamledit.MappingNodeAction {
-	var actions []yamledit.MappingNodeAction
-	name, ok := strings.CutSuffix(file.Name, ".exe")
-	if ok {
-		actions = append(actions, yamledit.SetKey("name", name, nil))
+func validatePackage(pkgName string, pkgInfo *registry.PackageInfo) error {
+	if strings.Contains(pkgName, ".") {
+		return nil
 	}
-	src, ok := strings.CutSuffix(file.Src, ".exe")
-	if ok {
-		actions = append(actions, yamledit.SetKey("src", src, nil))
+	if pkgInfo.RepoOwner == "" {
+		return errors.New("repo_owner must be specified if package name doesn't include period")
 	}
-	if name == src {
-		actions = append(actions, yamledit.RemoveKeys("src"))
+	if pkgInfo.RepoName == "" {
+		return errors.New("if package name doesn't include period, repo_name must be specified")
 	}
-	return actions
+	return nil
 }
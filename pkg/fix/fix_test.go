@@ -0,0 +1,147 @@
+package fix_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/fix"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	fixedYAML = `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: gh
+`
+	unfixedYAML = `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    files:
+      - name: tfcmt.exe
+`
+	// files is removed by redundant-files after .exe is removed by exe-suffix.
+	fixedTfcmtYAML = `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+`
+)
+
+func TestFix_all(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", fixedYAML)
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", unfixedYAML)
+	logger := slog.New(slog.DiscardHandler)
+
+	buf := &strings.Builder{}
+	if err := fix.Fix(t.Context(), logger, &fix.Options{
+		All:     true,
+		Check:   true,
+		Workers: 2,
+		Stdout:  buf,
+	}, nil); err == nil {
+		t.Fatal("error should be returned in check mode")
+	}
+	exp := "pkgs/suzuki-shunsuke/tfcmt/registry.yaml\n"
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("output (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(unfixedYAML, testutil.ReadFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml")); diff != "" {
+		t.Fatalf("the file must not be changed in check mode (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := fix.Fix(t.Context(), logger, &fix.Options{
+		All:    true,
+		Stdout: buf,
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("output (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(fixedTfcmtYAML, testutil.ReadFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml")); diff != "" {
+		t.Fatalf("fixed file (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := fix.Fix(t.Context(), logger, &fix.Options{
+		All:    true,
+		Check:  true,
+		Stdout: buf,
+	}, nil); err != nil {
+		t.Fatalf("no file should be changed: %v\n%s", err, buf.String())
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("no file should be output: %s", buf.String())
+	}
+}
+
+func TestFix_invalidPkgYAML(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", unfixedYAML)
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/pkg.yaml", "packages: [\n")
+	buf := &strings.Builder{}
+	// pkg.yaml isn't needed to fix registry.yaml
+	if err := fix.Fix(t.Context(), slog.New(slog.DiscardHandler), &fix.Options{
+		All:    true,
+		Stdout: buf,
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fixedTfcmtYAML, testutil.ReadFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml")); diff != "" {
+		t.Fatalf("fixed file (-want +got):\n%s", diff)
+	}
+}
+
+func TestFix_changedSince(t *testing.T) {
+	testutil.SetGitEnv(t)
+	t.Chdir(t.TempDir())
+	testutil.Git(t, "", "init", "-b", "main")
+	// This file isn't fixed because it isn't changed.
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", unfixedYAML)
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", fixedYAML)
+	testutil.Git(t, "", "add", ".")
+	testutil.Git(t, "", "commit", "-m", "init")
+
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: gh.exe
+`)
+	testutil.WriteFile(t, "pkgs/foo/bar/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    files:
+      - name: bar.exe
+`)
+
+	buf := &strings.Builder{}
+	if err := fix.Fix(t.Context(), slog.New(slog.DiscardHandler), &fix.Options{
+		ChangedSince: "HEAD",
+		Stdout:       buf,
+		Repo:         git.New(slog.New(slog.DiscardHandler), ""),
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	exp := "pkgs/cli/cli/registry.yaml\npkgs/foo/bar/registry.yaml\n"
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("output (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(fixedYAML, testutil.ReadFile(t, "pkgs/cli/cli/registry.yaml")); diff != "" {
+		t.Fatalf("fixed file (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(unfixedYAML, testutil.ReadFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml")); diff != "" {
+		t.Fatalf("unchanged file must not be fixed (-want +got):\n%s", diff)
+	}
+}
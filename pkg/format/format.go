@@ -0,0 +1,282 @@
+// Package format formats pkgs/**/registry.yaml.
+// Keys of packages are sorted in the canonical order, and indentation and quotes are normalized.
+// Comments are kept.
+package format
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+)
+
+// indent is the number of spaces of an indentation level.
+const indent = 2
+
+// packageKeys is the canonical order of keys of packages.
+// type, repo_owner, repo_name, and description come first as in existing registry.yaml files,
+// and the rest follows the order of fields of registry.PackageInfo of aqua.
+var packageKeys = []string{ //nolint:gochecknoglobals
+	"type",
+	"repo_owner",
+	"repo_name",
+	"description",
+	"name",
+	"aliases",
+	"search_words",
+	"link",
+	"asset",
+	"crate",
+	"url",
+	"path",
+	"format",
+	"version_filter",
+	"version_prefix",
+	"go_version_path",
+	"rosetta2",
+	"windows_arm_emulation",
+	"no_asset",
+	"version_source",
+	"complete_windows_ext",
+	"windows_ext",
+	"private",
+	"error_message",
+	"append_ext",
+	"cargo",
+	"build",
+	"overrides",
+	"format_overrides",
+	"files",
+	"replacements",
+	"supported_envs",
+	"checksum",
+	"cosign",
+	"slsa_provenance",
+	"minisign",
+	"github_artifact_attestations",
+	"vars",
+	"version_constraint",
+	"version_overrides",
+}
+
+var (
+	// versionOverrideKeys is the canonical order of keys of version_overrides.
+	versionOverrideKeys = slices.Concat([]string{"version_constraint"}, packageKeys) //nolint:gochecknoglobals
+	// overrideKeys is the canonical order of keys of overrides.
+	overrideKeys = slices.Concat([]string{"goos", "goarch"}, packageKeys) //nolint:gochecknoglobals
+	// fileKeys is the canonical order of keys of files.
+	fileKeys = []string{"name", "src", "dir", "link", "hard"} //nolint:gochecknoglobals
+	// formatOverrideKeys is the canonical order of keys of format_overrides.
+	formatOverrideKeys = []string{"goos", "format"} //nolint:gochecknoglobals
+)
+
+// PackageKeyRank returns the position of the key of packages in the canonical order.
+// Unknown keys are ranked after known keys.
+func PackageKeyRank(key string) int {
+	if i := slices.Index(packageKeys, key); i >= 0 {
+		return i
+	}
+	return len(packageKeys)
+}
+
+// Format formats registry.yaml.
+func Format(b []byte) ([]byte, error) {
+	file, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	for _, doc := range file.Docs {
+		if doc.Body == nil {
+			continue
+		}
+		root := doc.Body
+		normalizeQuotes(root)
+		if m := mappingValues(root); m != nil {
+			for _, mv := range m {
+				if mv.Key.String() == "packages" {
+					formatSequence(mv.Value, packageKeys)
+				}
+			}
+		}
+		reindent(root, 1)
+	}
+	s := file.String()
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return []byte(s), nil
+}
+
+// mappingValues returns values of the mapping.
+// It returns nil if the node isn't a mapping.
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	default:
+		return nil
+	}
+}
+
+func formatSequence(node ast.Node, keys []string) {
+	seq, ok := node.(*ast.SequenceNode)
+	if !ok {
+		return
+	}
+	for i, value := range seq.Values {
+		formatMapping(value, keys)
+		hoistComment(seq, i)
+	}
+}
+
+// hoistComment moves the comment of the first key of the i-th entry to the head of the entry.
+// Otherwise, the comment is output after "- " and is lost when the file is parsed again.
+func hoistComment(seq *ast.SequenceNode, i int) {
+	values := mappingValues(seq.Values[i])
+	if len(values) == 0 || values[0].Comment == nil {
+		return
+	}
+	comment := values[0].Comment
+	values[0].Comment = nil
+	if len(seq.ValueHeadComments) != len(seq.Values) {
+		seq.ValueHeadComments = make([]*ast.CommentGroupNode, len(seq.Values))
+	}
+	if head := seq.ValueHeadComments[i]; head != nil {
+		head.Comments = append(head.Comments, comment.Comments...)
+		return
+	}
+	seq.ValueHeadComments[i] = comment
+}
+
+// formatMapping sorts keys of the mapping and formats children recursively.
+func formatMapping(node ast.Node, keys []string) {
+	m, ok := node.(*ast.MappingNode)
+	if ok {
+		sortKeys(m, keys)
+	}
+	for _, mv := range mappingValues(node) {
+		switch mv.Key.String() {
+		case "version_overrides":
+			formatSequence(mv.Value, versionOverrideKeys)
+		case "overrides":
+			formatSequence(mv.Value, overrideKeys)
+		case "files":
+			formatSequence(mv.Value, fileKeys)
+		case "format_overrides":
+			formatSequence(mv.Value, formatOverrideKeys)
+		}
+	}
+}
+
+// sortKeys sorts keys in the canonical order.
+// Unknown keys are put after known keys in the original order.
+func sortKeys(m *ast.MappingNode, keys []string) {
+	rank := func(mv *ast.MappingValueNode) int {
+		if i := slices.Index(keys, mv.Key.String()); i >= 0 {
+			return i
+		}
+		return len(keys)
+	}
+	// The comment of the mapping is output before the first key, so it's moved to the first key.
+	if m.Comment != nil && len(m.Values) > 0 && m.Values[0].Comment == nil {
+		m.Values[0].Comment = m.Comment
+		m.Comment = nil
+	}
+	slices.SortStableFunc(m.Values, func(a, b *ast.MappingValueNode) int {
+		return rank(a) - rank(b)
+	})
+}
+
+// normalizeQuotes removes unnecessary quotes and replaces single quotes with double quotes.
+// Multi-line strings and strings including double quotes or backslashes are kept as they are.
+func normalizeQuotes(root ast.Node) {
+	ast.Walk(visitorFunc(func(node ast.Node) {
+		n, ok := node.(*ast.StringNode)
+		if !ok || strings.ContainsAny(n.Value, "\r\n") {
+			return
+		}
+		switch n.Token.Type {
+		case token.SingleQuoteType, token.DoubleQuoteType:
+		default:
+			return
+		}
+		if !token.IsNeedQuoted(n.Value) {
+			n.Token.Type = token.StringType
+			return
+		}
+		if n.Token.Type == token.SingleQuoteType && !strings.ContainsAny(n.Value, `"\`) {
+			n.Token.Type = token.DoubleQuoteType
+		}
+	}), root)
+}
+
+type visitorFunc func(node ast.Node)
+
+func (f visitorFunc) Visit(node ast.Node) ast.Visitor {
+	f(node)
+	return f
+}
+
+// reindent sets columns of tokens so that mappings are indented by two spaces
+// and sequences are indented by two spaces from their keys.
+// column is the column where the node starts.
+func reindent(node ast.Node, column int) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		if n.IsFlowStyle {
+			return
+		}
+		for _, mv := range n.Values {
+			reindent(mv, column)
+		}
+	case *ast.MappingValueNode:
+		n.Key.GetToken().Position.Column = column
+		switch value := n.Value.(type) {
+		case *ast.MappingNode, *ast.MappingValueNode:
+			reindent(value, column+indent)
+		case *ast.SequenceNode:
+			reindent(value, column+indent)
+		case *ast.LiteralNode:
+			reindentLiteral(value, column+indent)
+		}
+	case *ast.SequenceNode:
+		if n.IsFlowStyle {
+			return
+		}
+		n.Start.Position.Column = column
+		for _, value := range n.Values {
+			reindent(value, column+indent)
+		}
+	}
+}
+
+// reindentLiteral sets the indentation of lines of the literal block scalar.
+func reindentLiteral(n *ast.LiteralNode, column int) {
+	tk := n.Value.GetToken()
+	lines := strings.Split(tk.Origin, "\n")
+	minIndent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if i := len(line) - len(strings.TrimLeft(line, " ")); minIndent < 0 || i < minIndent {
+			minIndent = i
+		}
+	}
+	if minIndent < 0 {
+		return
+	}
+	space := strings.Repeat(" ", column-1)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines[i] = space + line[minIndent:]
+	}
+	tk.Origin = strings.Join(lines, "\n")
+}
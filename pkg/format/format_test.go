@@ -0,0 +1,224 @@
+package format_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/format"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name string
+		src  string
+		exp  string
+	}{
+		{
+			name: "formatted",
+			src: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: gh
+`,
+			exp: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: gh
+`,
+		},
+		{
+			name: "sort keys and keep comments",
+			src: `# yaml-language-server: $schema=https://example.com
+packages:
+  # pkg comment
+  - files:
+      - src: foo # line comment
+        name: foo
+    # before type
+    type: github_release # type comment
+    description: A tool
+    repo_name: cli
+    repo_owner: cli
+    unknown: foo
+    version_constraint: "false"
+    version_overrides:
+      - asset: "{{.OS}}"
+        version_constraint: "true"
+        overrides:
+          - format: zip
+            goos: windows
+        format_overrides:
+          - format: zip
+            goos: windows
+`,
+			exp: `# yaml-language-server: $schema=https://example.com
+packages:
+  # pkg comment
+  # before type
+  - type: github_release # type comment
+    repo_owner: cli
+    repo_name: cli
+    description: A tool
+    files:
+      - name: foo
+        src: foo # line comment
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: "true"
+        asset: "{{.OS}}"
+        overrides:
+          - goos: windows
+            format: zip
+        format_overrides:
+          - goos: windows
+            format: zip
+    unknown: foo
+`,
+		},
+		{
+			name: "name and aliases",
+			src: `packages:
+  - name: cli/gh
+    aliases:
+      - name: cli/cli
+    type: github_release
+    repo_owner: cli
+    repo_name: cli
+    asset: gh.tar.gz
+    description: GitHub CLI
+`,
+			exp: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    description: GitHub CLI
+    name: cli/gh
+    aliases:
+      - name: cli/cli
+    asset: gh.tar.gz
+`,
+		},
+		{
+			name: "normalize indentation and quotes",
+			src: `packages:
+    -   type: 'github_release'
+        repo_owner: "cli"
+        repo_name: cli
+        description: |
+              multi
+              line
+        asset: 'gh_{{.OS}}.tar.gz'
+        version_constraint: "false"
+        replacements:
+            darwin: 'macOS'
+        files:
+        - name: gh
+          src: '{{.AssetWithoutExt}}/gh'
+        supported_envs: [darwin, 'linux']
+`,
+			exp: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    description: |
+      multi
+      line
+    asset: gh_{{.OS}}.tar.gz
+    files:
+      - name: gh
+        src: "{{.AssetWithoutExt}}/gh"
+    replacements:
+      darwin: macOS
+    supported_envs: [darwin, linux]
+    version_constraint: "false"
+`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := format.Format([]byte(d.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(d.exp, string(b)); diff != "" {
+				t.Fatalf("formatted (-want +got):\n%s", diff)
+			}
+			// Format must be idempotent.
+			b, err = format.Format(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(d.exp, string(b)); diff != "" {
+				t.Fatalf("formatted twice (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	p := filepath.Join("pkgs", "cli", "cli", "registry.yaml")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	src := `packages:
+  - repo_name: cli
+    repo_owner: cli
+    type: github_release
+`
+	if err := os.WriteFile(p, []byte(src), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+
+	buf := &strings.Builder{}
+	if err := format.Run(t.Context(), slog.New(slog.DiscardHandler), &format.Config{
+		Check:  true,
+		Stdout: buf,
+	}); err == nil {
+		t.Fatal("error should be returned in check mode")
+	}
+	if diff := cmp.Diff("pkgs/cli/cli/registry.yaml\n", buf.String()); diff != "" {
+		t.Fatalf("output (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := format.Run(t.Context(), slog.New(slog.DiscardHandler), &format.Config{
+		Args:   []string{"cli/cli"},
+		Stdout: buf,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`
+	if diff := cmp.Diff(exp, string(b)); diff != "" {
+		t.Fatalf("formatted file (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := format.Run(t.Context(), slog.New(slog.DiscardHandler), &format.Config{
+		Check:  true,
+		Stdout: buf,
+	}); err != nil {
+		t.Fatalf("the file should be formatted: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("no file should be output: %s", buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/aquaproj/registry-tool/pkg/pkgfile"
+	"github.com/suzuki-shunsuke/slog-error/slogerr"
+)
+
+var errUnformatted = errors.New("files aren't formatted. Please run argd fmt")
+
+// Config is the configuration of Run.
+type Config struct {
+	// Args are package names or paths to pkgs/**/registry.yaml.
+	// If it's empty, all pkgs/**/registry.yaml are formatted.
+	Args []string
+	// Check doesn't write files, and Run returns an error if any file isn't formatted.
+	Check bool
+	// Stdout is the writer paths to changed files are output to.
+	Stdout io.Writer
+}
+
+// Run formats files and outputs paths to changed files.
+func Run(ctx context.Context, logger *slog.Logger, cfg *Config) error {
+	paths, err := pkgfile.Targets(ctx, logger, cfg.Args)
+	if err != nil {
+		return err
+	}
+	num := 0
+	for _, p := range paths {
+		changed, err := formatFile(p, cfg.Check)
+		if err != nil {
+			return slogerr.With(err, "file", p) //nolint:wrapcheck
+		}
+		if !changed {
+			continue
+		}
+		num++
+		fmt.Fprintln(cfg.Stdout, filepath.ToSlash(p))
+	}
+	if cfg.Check && num > 0 {
+		return slogerr.With(errUnformatted, "num_of_files", num) //nolint:wrapcheck
+	}
+	return nil
+}
+
+// formatFile formats the file and returns true if the file is changed.
+// If check is true, the file isn't written.
+func formatFile(p string, check bool) (bool, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return false, fmt.Errorf("read %s: %w", p, err)
+	}
+	formatted, err := Format(b)
+	if err != nil {
+		return false, fmt.Errorf("format %s: %w", p, err)
+	}
+	if bytes.Equal(b, formatted) {
+		return false, nil
+	}
+	if check {
+		return true, nil
+	}
+	if err := pkgfile.Write(p, formatted); err != nil {
+		return false, err //nolint:wrapcheck
+	}
+	return true, nil
+}
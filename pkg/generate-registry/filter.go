@@ -0,0 +1,118 @@
+package genrg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// filtered returns true if a subset of packages is selected.
+func (o *Options) filtered() bool {
+	return len(o.Include) > 0 || len(o.Exclude) > 0 || o.FromList != ""
+}
+
+func (o *Options) output() string {
+	if o.Output == "" {
+		return registryPath
+	}
+	return o.Output
+}
+
+// filterFragments selects fragments by Include, Exclude, and FromList keeping the order.
+// If neither Include nor FromList is set, all packages are selected before Exclude is applied.
+// Patterns are matched with package names and aliases, and names in FromList are resolved through aliases.
+func filterFragments(fragments []fragment, opts *Options) ([]fragment, error) {
+	if !opts.filtered() {
+		return fragments, nil
+	}
+	for _, pattern := range slices.Concat(opts.Include, opts.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	selected := make([]bool, len(fragments))
+	if len(opts.Include) == 0 && opts.FromList == "" {
+		for i := range selected {
+			selected[i] = true
+		}
+	}
+	for i, f := range fragments {
+		if matchAny(opts.Include, f) {
+			selected[i] = true
+		}
+	}
+	if opts.FromList != "" {
+		names, err := readPackageList(opts.FromList)
+		if err != nil {
+			return nil, err
+		}
+		index := nameIndex(fragments)
+		for _, name := range names {
+			i, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("package %s in %s isn't found", name, opts.FromList)
+			}
+			selected[i] = true
+		}
+	}
+	var ret []fragment
+	for i, f := range fragments {
+		if selected[i] && !matchAny(opts.Exclude, f) {
+			ret = append(ret, f)
+		}
+	}
+	return ret, nil
+}
+
+// nameIndex returns a map from package names and aliases to indices of fragments.
+func nameIndex(fragments []fragment) map[string]int {
+	index := make(map[string]int, len(fragments))
+	for i, f := range fragments {
+		index[f.Name] = i
+		for _, alias := range f.Package.Info.Aliases {
+			index[alias.Name] = i
+		}
+	}
+	return index
+}
+
+// matchAny returns true if the package name or any alias matches any pattern.
+func matchAny(patterns []string, f fragment) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, f.Name); ok {
+			return true
+		}
+		for _, alias := range f.Package.Info.Aliases {
+			if ok, _ := path.Match(pattern, alias.Name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readPackageList reads a file including a package name per line.
+// Empty lines and lines starting with # are ignored.
+func readPackageList(p string) ([]string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", p, err)
+	}
+	defer f.Close()
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", p, err)
+	}
+	return names, nil
+}
@@ -2,16 +2,32 @@ package genrg
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
+	"sync"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/diff"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/pkgfile"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
 )
 
+const registryPath = "registry.yaml"
+
 const rHeader = `---
 # Don't edit registry.yaml manually.
 # registry.yaml is generated by command "aqua-registry gr".
@@ -19,37 +35,194 @@ packages:
 `
 
 // registryFile is a discovered pkgs/<pkg>/registry.yaml. Dir is precomputed so
-// the sort comparator does not recompute filepath.Dir on every comparison.
+// the sort comparator does not recompute path.Dir on every comparison.
+// Path and Dir are slash-separated on every OS.
 type registryFile struct {
 	Path string
 	Dir  string
 }
 
-func GenerateRegistry(ctx context.Context) error {
-	files, err := listRegistryFiles(ctx)
+// fragment is the content of pkgs/<pkg>/registry.yaml embedded in registry.yaml.
+type fragment struct {
+	Name    string
+	Path    string
+	Text    string
+	Package *parsedPackage
+}
+
+// GenerateRegistry generates registry.yaml and additional outputs specified by opts.
+func GenerateRegistry(ctx context.Context, opts *Options) error {
+	outputs, _, err := buildRegistry(ctx, opts)
 	if err != nil {
 		return err
 	}
-	rgFile, err := os.Create("registry.yaml")
+	for _, o := range outputs {
+		if err := pkgfile.Write(o.Path, o.Data); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+	return nil
+}
+
+// CheckRegistry checks if registry.yaml and additional outputs specified by opts are up to date without updating them.
+// If they are outdated, it outputs the unified diff and packages whose fragments differ to w and returns an error.
+func CheckRegistry(ctx context.Context, w io.Writer, opts *Options) error {
+	outputs, fragments, err := buildRegistry(ctx, opts)
 	if err != nil {
-		return fmt.Errorf("open registry.yaml: %w", err)
+		return err
+	}
+	var outdated []string
+	for _, o := range outputs {
+		current, err := os.ReadFile(o.Path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("read %s: %w", o.Path, err)
+		}
+		if bytes.Equal(current, o.Data) {
+			continue
+		}
+		outdated = append(outdated, o.Path)
+		if _, err := w.Write(diff.Diff(o.Path+" (current)", current, o.Path+" (generated)", o.Data)); err != nil {
+			return fmt.Errorf("output the diff of %s: %w", o.Path, err)
+		}
+		if o.Path != opts.output() {
+			continue
+		}
+		if pkgs := changedPackages(current, fragments); len(pkgs) > 0 {
+			fmt.Fprintln(w, "\nPackages whose fragments differ:")
+			for _, pkg := range pkgs {
+				fmt.Fprintln(w, "- "+pkg)
+			}
+		}
 	}
-	defer rgFile.Close()
-	if _, err := rgFile.WriteString(rHeader); err != nil {
-		return fmt.Errorf("write a header to registry.yaml: %w", err)
+	if len(outdated) == 0 {
+		return nil
 	}
-	for _, f := range files {
-		lines, err := readRegistryFile(f.Path)
-		if err != nil {
-			return fmt.Errorf("read a registry.yaml: %w", err)
+	return fmt.Errorf("%s is outdated. Please run 'argd gr' with the same options", strings.Join(outdated, ", "))
+}
+
+// changedPackages returns packages whose fragments differ from registry.yaml.
+// Packages are compared by the exact name, and packages removed from registry.yaml are also returned.
+func changedPackages(current []byte, fragments []fragment) []string {
+	texts := packageTexts(current)
+	var pkgs []string
+	for _, f := range fragments {
+		if text, ok := texts[f.Name]; !ok || text != f.Text {
+			pkgs = append(pkgs, f.Name)
 		}
-		if _, err := rgFile.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
-			return fmt.Errorf("write registry.yaml: %w", err)
+		delete(texts, f.Name)
+	}
+	removed := slices.Sorted(maps.Keys(texts))
+	for _, name := range removed {
+		pkgs = append(pkgs, name+" (removed)")
+	}
+	return pkgs
+}
+
+// packageTexts splits registry.yaml into the text of each package.
+// Comments just before a package belong to the package as they do in the fragment.
+// It returns an empty map if registry.yaml can't be parsed.
+func packageTexts(b []byte) map[string]string {
+	texts := map[string]string{}
+	file, err := parser.ParseBytes(b, 0)
+	if err != nil || len(file.Docs) != 1 {
+		return texts
+	}
+	cfg := &registry.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return texts
+	}
+	nodes := sequenceValues(file.Docs[0].Body, "packages")
+	if len(nodes) != len(cfg.PackageInfos) {
+		return texts
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	starts := make([]int, len(nodes)+1)
+	for i := range nodes {
+		start := nodeLine(nodes, i) - 1
+		for start > 0 && strings.HasPrefix(strings.TrimSpace(lines[start-1]), "#") {
+			start--
 		}
+		starts[i] = start
 	}
-	return nil
+	starts[len(nodes)] = len(lines)
+	for i, pkg := range cfg.PackageInfos {
+		if starts[i] >= starts[i+1] {
+			continue
+		}
+		texts[pkg.GetName()] = strings.Join(lines[starts[i]:starts[i+1]], "")
+	}
+	return texts
+}
+
+// buildRegistry builds registry.yaml and additional outputs in memory.
+// registry.yaml is the first output.
+func buildRegistry(ctx context.Context, opts *Options) ([]output, []fragment, error) {
+	files, err := listRegistryFiles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	fragments, err := readFragments(ctx, files, runtime.GOMAXPROCS(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	if collisions := findCollisions(fragments); len(collisions) > 0 {
+		return nil, nil, &collisionsError{Collisions: collisions}
+	}
+	fragments, err = filterFragments(fragments, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString(rHeader)
+	for _, f := range fragments {
+		buf.WriteString(f.Text)
+	}
+	extra, err := buildOutputs(opts, fragments)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append([]output{{Path: opts.output(), Data: buf.Bytes()}}, extra...), fragments, nil
 }
 
+// readFragments reads files concurrently with at most `workers` goroutines.
+// Fragments are returned in the same order as files.
+// If some files fail, the error of the first file in the order is returned.
+func readFragments(ctx context.Context, files []registryFile, workers int) ([]fragment, error) {
+	fragments := make([]fragment, len(files))
+	errs := make([]error, len(files))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(files)) {
+		wg.Go(func() {
+			for i := range indices {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				f, err := readRegistryFile(files[i])
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				fragments[i] = f
+			}
+		})
+	}
+	for i := range files {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fragments, nil
+}
+
+// writeFile writes data to a temporary file in the same directory and renames it to path,
+// so path isn't broken even if writing fails.
 func listRegistryFiles(ctx context.Context) ([]registryFile, error) {
 	canonical := canonicalCaseMap(ctx)
 	files := []registryFile{}
@@ -67,7 +240,7 @@ func listRegistryFiles(ctx context.Context) ([]registryFile, error) {
 		if c, ok := canonical[strings.ToLower(slash)]; ok {
 			slash = c
 		}
-		files = append(files, registryFile{Path: slash, Dir: filepath.Dir(slash)})
+		files = append(files, registryFile{Path: slash, Dir: path.Dir(slash)})
 		return nil
 	}); err != nil {
 		return nil, fmt.Errorf("find registry.yaml in the directory pkgs: %w", err)
@@ -84,29 +257,29 @@ func listRegistryFiles(ctx context.Context) ([]registryFile, error) {
 // otherwise make registry.yaml ordering depend on the contributor's machine.
 // Returns nil if git is unavailable or pkgs/ is not tracked.
 func canonicalCaseMap(ctx context.Context) map[string]string {
-	out, err := exec.CommandContext(ctx, "git", "ls-files", "-z", "--", "pkgs").Output()
-	if err != nil {
-		return nil
-	}
-	s := strings.TrimRight(string(out), "\x00")
-	if s == "" {
+	files, err := git.New(slog.Default(), "").LsFiles(ctx, "pkgs")
+	if err != nil || len(files) == 0 {
 		return nil
 	}
-	m := make(map[string]string)
-	for p := range strings.SplitSeq(s, "\x00") {
+	m := make(map[string]string, len(files))
+	for _, p := range files {
 		m[strings.ToLower(p)] = p
 	}
 	return m
 }
 
-func readRegistryFile(registryFilePath string) ([]string, error) {
-	lines := []string{}
-	f, err := os.Open(registryFilePath)
+func readRegistryFile(f registryFile) (fragment, error) {
+	b, err := os.ReadFile(f.Path)
 	if err != nil {
-		return nil, fmt.Errorf("open %s: %w", registryFilePath, err)
+		return fragment{}, fmt.Errorf("read %s: %w", f.Path, err)
 	}
-	defer f.Close()
-	scanner := bufio.NewScanner(f)
+	pkgName := strings.TrimPrefix(f.Dir, "pkgs/")
+	pkg, err := parseFragment(f.Path, pkgName, b)
+	if err != nil {
+		return fragment{}, err
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
 	for scanner.Scan() {
 		line := scanner.Text()
 		if strings.HasPrefix(line, "packages:") || line == "---" || strings.HasPrefix(line, "# yaml-language-server:") {
@@ -115,7 +288,12 @@ func readRegistryFile(registryFilePath string) ([]string, error) {
 		lines = append(lines, line)
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan a file: %w", err)
+		return fragment{}, fmt.Errorf("scan a file: %w", err)
 	}
-	return lines, nil
+	return fragment{
+		Name:    pkgName,
+		Path:    f.Path,
+		Text:    strings.Join(lines, "\n") + "\n",
+		Package: pkg,
+	}, nil
 }
@@ -0,0 +1,493 @@
+package genrg
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckRegistry(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`)
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+`)
+	ctx := t.Context()
+	if err := GenerateRegistry(ctx, &Options{}); err != nil {
+		t.Fatal(err)
+	}
+	buf := &strings.Builder{}
+	if err := CheckRegistry(ctx, buf, &Options{}); err != nil {
+		t.Fatalf("registry.yaml should be up to date: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("nothing should be outputted: %s", buf.String())
+	}
+
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    description: Fork of mercari/tfnotify
+`)
+	if err := CheckRegistry(ctx, buf, &Options{}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	out := buf.String()
+	if !strings.Contains(out, "+    description: Fork of mercari/tfnotify\n") {
+		t.Fatalf("the diff should be outputted: %s", out)
+	}
+	if !strings.Contains(out, "- suzuki-shunsuke/tfcmt\n") || strings.Contains(out, "- cli/cli\n") {
+		t.Fatalf("only changed packages should be listed: %s", out)
+	}
+}
+
+func TestCheckRegistry_notExist(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`)
+	buf := &strings.Builder{}
+	if err := CheckRegistry(t.Context(), buf, &Options{}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	if !strings.Contains(buf.String(), "- cli/cli\n") {
+		t.Fatalf("the package should be listed: %s", buf.String())
+	}
+}
+
+func TestCheckRegistry_changedPackages(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/foo/bar/registry.yaml", "packages:\n  # The CLI of bar\n  - type: github_release\n    repo_owner: foo\n    repo_name: bar\n")
+	testutil.WriteFile(t, "pkgs/foo/bar-cli/registry.yaml", "packages:\n  - type: github_release\n    repo_owner: foo\n    repo_name: bar-cli\n")
+	testutil.WriteFile(t, "pkgs/foo/baz/registry.yaml", "packages:\n  - type: github_release\n    repo_owner: foo\n    repo_name: baz\n")
+	ctx := t.Context()
+	if err := GenerateRegistry(ctx, &Options{}); err != nil {
+		t.Fatal(err)
+	}
+	testutil.WriteFile(t, "pkgs/foo/bar-cli/registry.yaml", "packages:\n  - type: github_release\n    repo_owner: foo\n    repo_name: bar-cli\n    description: bar\n")
+	if err := os.RemoveAll("pkgs/foo/baz"); err != nil {
+		t.Fatal(err)
+	}
+	buf := &strings.Builder{}
+	if err := CheckRegistry(ctx, buf, &Options{}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	_, out, _ := strings.Cut(buf.String(), "Packages whose fragments differ:\n")
+	if diff := cmp.Diff("- foo/bar-cli\n- foo/baz (removed)\n", out); diff != "" {
+		t.Fatalf("changed packages (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseFragment(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name    string
+		pkgName string
+		content string
+		exp     string
+	}{
+		{
+			name:    "valid",
+			pkgName: "cli/cli",
+			content: `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`,
+		},
+		{
+			name:    "name",
+			pkgName: "golang.org/x/tools/gopls",
+			content: `packages:
+  - type: go_install
+    name: golang.org/x/tools/gopls
+    path: golang.org/x/tools/gopls
+`,
+		},
+		{
+			name:    "two packages",
+			pkgName: "cli/cli",
+			content: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+  - type: github_release
+    repo_owner: cli
+    repo_name: go-gh
+`,
+			exp: "pkgs/cli/cli/registry.yaml:5: packages must include only one package",
+		},
+		{
+			name:    "empty",
+			pkgName: "cli/cli",
+			content: "packages:\n",
+			exp:     "pkgs/cli/cli/registry.yaml:1: packages is empty",
+		},
+		{
+			name:    "name mismatch",
+			pkgName: "cli/cli",
+			content: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: go-gh
+`,
+			exp: `pkgs/cli/cli/registry.yaml:2: the package name "cli/go-gh" must match the path "cli/cli"`,
+		},
+		{
+			name:    "bad indentation",
+			pkgName: "cli/cli",
+			content: `packages:
+  - type: github_release
+    repo_owner: cli
+   repo_name: cli
+`,
+			exp: "pkgs/cli/cli/registry.yaml:4:",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseFragment("pkgs/"+d.pkgName+"/registry.yaml", d.pkgName, []byte(d.content))
+			if d.exp == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("error should be returned")
+			}
+			if !strings.HasPrefix(err.Error(), d.exp) {
+				t.Fatalf("wanted %q, got %q", d.exp, err.Error())
+			}
+		})
+	}
+}
+
+func TestReadFragments(t *testing.T) {
+	t.Chdir(t.TempDir())
+	pkgs := []string{"a/a", "b/b", "c/c", "d/d", "e/e"}
+	files := make([]registryFile, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		owner, name, _ := strings.Cut(pkg, "/")
+		path := "pkgs/" + pkg + "/registry.yaml"
+		testutil.WriteFile(t, path, "packages:\n  - type: github_release\n    repo_owner: "+owner+"\n    repo_name: "+name+"\n")
+		files = append(files, registryFile{Path: path, Dir: "pkgs/" + pkg})
+	}
+	fragments, err := readFragments(t.Context(), files, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range fragments {
+		if f.Name != pkgs[i] {
+			t.Fatalf("fragments must keep the order: wanted %s, got %s", pkgs[i], f.Name)
+		}
+	}
+}
+
+func TestListRegistryFiles_nested(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/hashicorp/terraform/cli/registry.yaml", "packages:\n  - name: hashicorp/terraform/cli\n    type: github_release\n    repo_owner: hashicorp\n    repo_name: terraform\n")
+	files, err := listRegistryFiles(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []registryFile{
+		{Path: "pkgs/hashicorp/terraform/cli/registry.yaml", Dir: "pkgs/hashicorp/terraform/cli"},
+	}
+	if diff := cmp.Diff(exp, files); diff != "" {
+		t.Fatalf("files (-want +got):\n%s", diff)
+	}
+	f, err := readRegistryFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "hashicorp/terraform/cli" {
+		t.Fatalf("wanted hashicorp/terraform/cli, got %s", f.Name)
+	}
+}
+
+func TestGenerateRegistry_invalidFragment(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "registry.yaml", "current\n")
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`)
+	testutil.WriteFile(t, "pkgs/cli/go-gh/registry.yaml", "packages:\n")
+	if err := GenerateRegistry(t.Context(), &Options{}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	b, err := os.ReadFile("registry.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "current\n" {
+		t.Fatalf("registry.yaml must not be changed: %s", string(b))
+	}
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("temporary files must be removed: %v", entries)
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    aliases:
+      - name: cli/gh
+`)
+	testutil.WriteFile(t, "pkgs/cli/gh/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: gh
+`)
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+`)
+	buf := &strings.Builder{}
+	if err := CheckNames(t.Context(), buf); err == nil {
+		t.Fatal("error should be returned")
+	}
+	exp := `name collision: cli/gh
+  pkgs/cli/cli/registry.yaml:6: alias "cli/gh" of cli/cli
+  pkgs/cli/gh/registry.yaml:2: name "cli/gh" of cli/gh
+`
+	if buf.String() != exp {
+		t.Fatalf("wanted\n%s\ngot\n%s", exp, buf.String())
+	}
+	if err := GenerateRegistry(t.Context(), &Options{}); err == nil {
+		t.Fatal("gr should fail")
+	}
+}
+
+func TestCheckNames_samePackage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// An alias differing from the package name only in case is a legitimate rename.
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    aliases:
+      - name: CLI/cli
+`)
+	buf := &strings.Builder{}
+	if err := CheckNames(t.Context(), buf); err != nil {
+		t.Fatalf("aliases of the same package must not conflict: %v\n%s", err, buf.String())
+	}
+
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    aliases:
+      - name: CLI/cli
+      - name: cli/gh
+      - name: cli/gh
+`)
+	err := CheckNames(t.Context(), buf)
+	if err == nil {
+		t.Fatal("error should be returned")
+	}
+	if err.Error() != "1 duplicate aliases are found" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp := `duplicate alias: cli/gh
+  pkgs/cli/cli/registry.yaml:7: alias "cli/gh" of cli/cli
+  pkgs/cli/cli/registry.yaml:8: alias "cli/gh" of cli/cli
+`
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("output (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateRegistry_outputs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    description: GitHub’s official command line tool
+    aliases:
+      - name: cli/gh
+`)
+	testutil.WriteFile(t, "pkgs/golang.org/x/tools/gopls/registry.yaml", `packages:
+  - type: go_install
+    name: golang.org/x/tools/gopls
+    path: golang.org/x/tools/gopls
+`)
+	ctx := t.Context()
+	opts := &Options{JSON: "registry.json", SearchIndex: "search-index.json"}
+	if err := GenerateRegistry(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile("search-index.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `[
+{"name":"cli/cli","aliases":["cli/gh"],"description":"GitHub’s official command line tool","repo":"cli/cli","type":"github_release"},
+{"name":"golang.org/x/tools/gopls","type":"go_install"}
+]
+`
+	if string(b) != exp {
+		t.Fatalf("wanted\n%s\ngot\n%s", exp, string(b))
+	}
+	b, err = os.ReadFile("registry.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"path": "golang.org/x/tools/gopls"`) {
+		t.Fatalf("registry.json should include packages: %s", string(b))
+	}
+	if err := CheckRegistry(ctx, io.Discard, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove("search-index.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckRegistry(ctx, io.Discard, opts); err == nil || !strings.Contains(err.Error(), "search-index.json") {
+		t.Fatalf("the search index should be outdated: %v", err)
+	}
+}
+
+func TestGenerateRegistry_subset(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, pkg := range []string{"cli/cli", "hashicorp/terraform", "hashicorp/vault", "suzuki-shunsuke/tfcmt"} {
+		owner, name, _ := strings.Cut(pkg, "/")
+		content := "packages:\n  - type: github_release\n    repo_owner: " + owner + "\n    repo_name: " + name + "\n"
+		if pkg == "cli/cli" {
+			content += "    aliases:\n      - name: cli/gh\n"
+		}
+		testutil.WriteFile(t, "pkgs/"+pkg+"/registry.yaml", content)
+	}
+	testutil.WriteFile(t, "packages.txt", "# vetted packages\ncli/gh\n\nhashicorp/vault\n")
+	ctx := t.Context()
+	opts := &Options{
+		Output:   "subset.yaml",
+		Include:  []string{"hashicorp/*", "suzuki-shunsuke/*"},
+		Exclude:  []string{"hashicorp/vault"},
+		FromList: "packages.txt",
+	}
+	if err := GenerateRegistry(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile("subset.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := rHeader + `  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    aliases:
+      - name: cli/gh
+  - type: github_release
+    repo_owner: hashicorp
+    repo_name: terraform
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+`
+	if string(b) != exp {
+		t.Fatalf("wanted\n%s\ngot\n%s", exp, string(b))
+	}
+	if _, err := os.Stat("registry.yaml"); err == nil {
+		t.Fatal("registry.yaml must not be generated")
+	}
+	if err := CheckRegistry(ctx, io.Discard, opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.Exclude = nil
+	if err := CheckRegistry(ctx, io.Discard, opts); err == nil {
+		t.Fatal("the subset should be outdated")
+	}
+
+	testutil.WriteFile(t, "packages.txt", "cli/unknown\n")
+	if err := GenerateRegistry(ctx, &Options{FromList: "packages.txt"}); err == nil {
+		t.Fatal("unknown packages should be rejected")
+	}
+}
+
+func TestOptionsFromConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("ARGD_CONFIG", "")
+	opts, err := OptionsFromConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"registry.yaml"}, opts.Paths()); diff != "" {
+		t.Fatalf("paths without the configuration file (-want +got):\n%s", diff)
+	}
+	testutil.WriteFile(t, "argd.yaml", `generate_registry:
+  json: registry.json
+  search_index: search-index.json
+`)
+	opts, err = OptionsFromConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"registry.yaml", "registry.json", "search-index.json"}, opts.Paths()); diff != "" {
+		t.Fatalf("paths (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateRegistry_subsetConfigOutputs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("ARGD_CONFIG", "")
+	testutil.WriteFile(t, "argd.yaml", `generate_registry:
+  json: registry.json
+`)
+	testutil.WriteFile(t, "pkgs/a/foo/registry.yaml", "packages:\n  - type: github_release\n    repo_owner: a\n    repo_name: foo\n")
+	testutil.WriteFile(t, "pkgs/b/bar/registry.yaml", "packages:\n  - type: github_release\n    repo_owner: b\n    repo_name: bar\n")
+	cfgOpts, err := OptionsFromConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := t.Context()
+	opts := &Options{Output: "registry.yaml"}
+	opts.SetConfigOutputs(cfgOpts)
+	if err := GenerateRegistry(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+	exp := testutil.ReadFile(t, "registry.json")
+	if !strings.Contains(exp, `"repo_owner": "b"`) {
+		t.Fatalf("registry.json must include all packages: %s", exp)
+	}
+
+	// A subset must not overwrite registry.json configured in argd.yaml
+	opts = &Options{Include: []string{"a/*"}, Output: "subset.yaml"}
+	opts.SetConfigOutputs(cfgOpts)
+	if opts.JSON != "" {
+		t.Fatalf("registry.json must not be generated with a subset: %s", opts.JSON)
+	}
+	if err := GenerateRegistry(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(exp, testutil.ReadFile(t, "registry.json")); diff != "" {
+		t.Fatalf("registry.json (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,205 @@
+package genrg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// nameEntry is a package name or an alias declared in pkgs/<pkg>/registry.yaml.
+type nameEntry struct {
+	Name string
+	// Package is the name of the package declaring the name.
+	Package string
+	Alias   bool
+	Path    string
+	Line    int
+}
+
+func (e *nameEntry) String() string {
+	kind := "name"
+	if e.Alias {
+		kind = "alias"
+	}
+	return fmt.Sprintf("%s:%d: %s %q of %s", e.Path, e.Line, kind, e.Name, e.Package)
+}
+
+// collision is a set of names and aliases which conflict with each other.
+// Names are compared case-insensitively because directories differing only in case conflict on case-insensitive filesystems.
+// Names of a same package don't conflict with each other, because an alias differing from the package name only in case is a legitimate rename.
+type collision struct {
+	Entries []*nameEntry
+	// Duplicate is true if a package declares the same name more than once.
+	// Entries belong to the package.
+	Duplicate bool
+}
+
+// collisionsError is returned if names or aliases conflict.
+type collisionsError struct {
+	Collisions []*collision
+}
+
+func (e *collisionsError) Error() string {
+	buf := &strings.Builder{}
+	buf.WriteString(summarizeCollisions(e.Collisions))
+	for _, c := range e.Collisions {
+		buf.WriteString("\n")
+		writeCollision(buf, c)
+	}
+	return buf.String()
+}
+
+// summarizeCollisions returns the number of collisions and duplicates.
+func summarizeCollisions(collisions []*collision) string {
+	duplicates := 0
+	for _, c := range collisions {
+		if c.Duplicate {
+			duplicates++
+		}
+	}
+	switch {
+	case duplicates == 0:
+		return fmt.Sprintf("%d name collisions are found", len(collisions))
+	case duplicates == len(collisions):
+		return fmt.Sprintf("%d duplicate aliases are found", duplicates)
+	default:
+		return fmt.Sprintf("%d name collisions and %d duplicate aliases are found", len(collisions)-duplicates, duplicates)
+	}
+}
+
+func writeCollision(w io.Writer, c *collision) {
+	if c.Duplicate {
+		fmt.Fprintf(w, "duplicate alias: %s\n", c.Entries[0].Name)
+	} else {
+		fmt.Fprintf(w, "name collision: %s\n", c.Entries[0].Name)
+	}
+	for _, e := range c.Entries {
+		fmt.Fprintf(w, "  %s\n", e)
+	}
+}
+
+// findCollisions builds the index of names and aliases of packages and returns conflicting ones.
+// Names are reported as a collision only if they belong to more than one package.
+// A name declared more than once in a package is reported as a duplicate.
+// Collisions are sorted in the order of fragments.
+func findCollisions(fragments []fragment) []*collision {
+	index, keys := indexNames(fragments)
+	var collisions []*collision
+	for _, key := range keys {
+		c := index[key]
+		if len(c.Entries) < 2 { //nolint:mnd
+			continue
+		}
+		if slices.ContainsFunc(c.Entries, func(e *nameEntry) bool {
+			return e.Package != c.Entries[0].Package
+		}) {
+			collisions = append(collisions, c)
+			continue
+		}
+		collisions = append(collisions, findDuplicates(c.Entries)...)
+	}
+	return collisions
+}
+
+// indexNames returns the index of names and aliases of packages.
+// Keys are lower-cased names, and they are also returned in the order of fragments.
+func indexNames(fragments []fragment) (map[string]*collision, []string) {
+	index := map[string]*collision{}
+	keys := []string{}
+	add := func(e *nameEntry) {
+		key := strings.ToLower(e.Name)
+		c, ok := index[key]
+		if !ok {
+			c = &collision{}
+			index[key] = c
+			keys = append(keys, key)
+		}
+		c.Entries = append(c.Entries, e)
+	}
+	for _, f := range fragments {
+		add(&nameEntry{
+			Name:    f.Name,
+			Package: f.Name,
+			Path:    f.Path,
+			Line:    f.Package.Line,
+		})
+		for i, alias := range f.Package.Info.Aliases {
+			add(&nameEntry{
+				Name:    alias.Name,
+				Package: f.Name,
+				Alias:   true,
+				Path:    f.Path,
+				Line:    f.Package.AliasLines[i],
+			})
+		}
+	}
+	return index, keys
+}
+
+// findDuplicates returns names declared more than once in a package.
+// Names differing only in case aren't duplicates.
+func findDuplicates(entries []*nameEntry) []*collision {
+	index := map[string]*collision{}
+	var duplicates []*collision
+	for _, e := range entries {
+		c, ok := index[e.Name]
+		if !ok {
+			index[e.Name] = &collision{Entries: []*nameEntry{e}, Duplicate: true}
+			continue
+		}
+		c.Entries = append(c.Entries, e)
+		if len(c.Entries) == 2 { //nolint:mnd
+			duplicates = append(duplicates, c)
+		}
+	}
+	return duplicates
+}
+
+// CheckNames checks if names and aliases of packages in pkgs conflict with each other.
+// It outputs collisions with locations of all sides to w and returns an error if collisions are found.
+func CheckNames(ctx context.Context, w io.Writer) error {
+	files, err := listRegistryFiles(ctx)
+	if err != nil {
+		return err
+	}
+	fragments, err := readFragments(ctx, files, runtime.GOMAXPROCS(0))
+	if err != nil {
+		return err
+	}
+	collisions := findCollisions(fragments)
+	if len(collisions) == 0 {
+		return nil
+	}
+	for _, c := range collisions {
+		writeCollision(w, c)
+	}
+	return errors.New(summarizeCollisions(collisions))
+}
+
+// CheckNewName returns an error if the name is already a name or an alias of a package other than pkgName.
+// Names are compared case-insensitively in the same way as CheckNames.
+func CheckNewName(ctx context.Context, name, pkgName string) error {
+	files, err := listRegistryFiles(ctx)
+	if err != nil {
+		return err
+	}
+	fragments, err := readFragments(ctx, files, runtime.GOMAXPROCS(0))
+	if err != nil {
+		return err
+	}
+	index, _ := indexNames(fragments)
+	c, ok := index[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+	for _, e := range c.Entries {
+		if e.Package != pkgName {
+			return fmt.Errorf("%s is already used: %s", name, e)
+		}
+	}
+	return nil
+}
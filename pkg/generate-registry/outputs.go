@@ -0,0 +1,147 @@
+package genrg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/config"
+)
+
+// Options are options of GenerateRegistry and CheckRegistry.
+type Options struct {
+	// JSON is the path to registry.json. If it's empty, registry.json isn't generated.
+	JSON string
+	// SearchIndex is the path to the search index. If it's empty, the search index isn't generated.
+	SearchIndex string
+	// Output is the path to the generated registry. If it's empty, registry.yaml is used.
+	Output string
+	// Include is glob patterns of packages to include. If it's empty, all packages are included unless FromList is set.
+	Include []string
+	// Exclude is glob patterns of packages to exclude.
+	Exclude []string
+	// FromList is the path to a file including a package name per line to include.
+	FromList string
+}
+
+// OptionsFromConfig returns Options generating registry.yaml and outputs configured in the configuration file.
+// Commands updating registry.yaml use it so that registry.json and the search index are kept up to date.
+func OptionsFromConfig() (*Options, error) {
+	cfg, err := config.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read the configuration file: %w", err)
+	}
+	return &Options{
+		JSON:        cfg.GenerateRegistry.JSON,
+		SearchIndex: cfg.GenerateRegistry.SearchIndex,
+	}, nil
+}
+
+// SetConfigOutputs sets JSON and SearchIndex unset by flags to the paths in cfg.
+// They are set only when the full registry.yaml is generated so that a subset doesn't overwrite them.
+func (o *Options) SetConfigOutputs(cfg *Options) {
+	if o.filtered() || o.output() != registryPath {
+		return
+	}
+	if o.JSON == "" {
+		o.JSON = cfg.JSON
+	}
+	if o.SearchIndex == "" {
+		o.SearchIndex = cfg.SearchIndex
+	}
+}
+
+// Paths returns paths to files generated by GenerateRegistry.
+// registry.yaml is the first path.
+func (o *Options) Paths() []string {
+	paths := []string{o.output()}
+	for _, p := range []string{o.JSON, o.SearchIndex} {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// output is a file generated by GenerateRegistry.
+type output struct {
+	Path string
+	Data []byte
+}
+
+// searchEntry is an entry of the search index.
+type searchEntry struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Repo        string   `json:"repo,omitempty"`
+	Type        string   `json:"type"`
+}
+
+// buildJSON builds registry.json from packages parsed from fragments.
+func buildJSON(fragments []fragment) ([]byte, error) {
+	cfg := &registry.Config{
+		PackageInfos: make(registry.PackageInfos, len(fragments)),
+	}
+	for i, f := range fragments {
+		cfg.PackageInfos[i] = f.Package.Info
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal packages as JSON: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// buildSearchIndex builds the search index from packages parsed from fragments.
+// Each entry is written in a line to make diffs readable.
+func buildSearchIndex(fragments []fragment) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("[\n")
+	for i, f := range fragments {
+		pkg := f.Package.Info
+		entry := &searchEntry{
+			Name:        f.Name,
+			Description: pkg.Description,
+			Type:        pkg.Type,
+		}
+		if pkg.HasRepo() {
+			entry.Repo = pkg.RepoOwner + "/" + pkg.RepoName
+		}
+		for _, alias := range pkg.Aliases {
+			entry.Aliases = append(entry.Aliases, alias.Name)
+		}
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("marshal a search index entry as JSON: %w", err)
+		}
+		buf.Write(b)
+		if i != len(fragments)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	return buf.Bytes(), nil
+}
+
+// buildOutputs builds additional outputs specified by opts.
+func buildOutputs(opts *Options, fragments []fragment) ([]output, error) {
+	var outputs []output
+	if opts.JSON != "" {
+		b, err := buildJSON(fragments)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output{Path: opts.JSON, Data: b})
+	}
+	if opts.SearchIndex != "" {
+		b, err := buildSearchIndex(fragments)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output{Path: opts.SearchIndex, Data: b})
+	}
+	return outputs, nil
+}
@@ -0,0 +1,113 @@
+package genrg
+
+import (
+	"errors"
+	"fmt"
+
+	wast "github.com/aquaproj/aqua/v2/pkg/ast"
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// fragmentError is an error of pkgs/<pkg>/registry.yaml with the position.
+type fragmentError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e *fragmentError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Message)
+}
+
+// parsedPackage is a package parsed from pkgs/<pkg>/registry.yaml.
+type parsedPackage struct {
+	Info *registry.PackageInfo
+	// Line is the line of the package.
+	Line int
+	// AliasLines are lines of aliases. The length is same as Info.Aliases.
+	AliasLines []int
+}
+
+// parseFragment parses and validates pkgs/<pkg>/registry.yaml.
+// The fragment must have only one package and the package name must match the path.
+func parseFragment(path, pkgName string, b []byte) (*parsedPackage, error) {
+	file, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return nil, newYAMLError(path, err)
+	}
+	cfg := &registry.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, newYAMLError(path, err)
+	}
+	if len(file.Docs) != 1 {
+		return nil, &fragmentError{Path: path, Line: 1, Message: "registry.yaml must have only one document"}
+	}
+	pkgs := sequenceValues(file.Docs[0].Body, "packages")
+	switch {
+	case len(cfg.PackageInfos) == 0:
+		return nil, &fragmentError{Path: path, Line: 1, Message: "packages is empty"}
+	case len(cfg.PackageInfos) > 1:
+		return nil, &fragmentError{Path: path, Line: nodeLine(pkgs, 1), Message: "packages must include only one package"}
+	}
+	pkg := &parsedPackage{
+		Info: cfg.PackageInfos[0],
+		Line: nodeLine(pkgs, 0),
+	}
+	if name := pkg.Info.GetName(); name != pkgName {
+		return nil, &fragmentError{
+			Path:    path,
+			Line:    pkg.Line,
+			Message: fmt.Sprintf("the package name %q must match the path %q", name, pkgName),
+		}
+	}
+	var aliases []ast.Node
+	if len(pkgs) > 0 {
+		aliases = sequenceValues(pkgs[0], "aliases")
+	}
+	pkg.AliasLines = make([]int, len(pkg.Info.Aliases))
+	for i := range pkg.Info.Aliases {
+		pkg.AliasLines[i] = nodeLine(aliases, i)
+	}
+	return pkg, nil
+}
+
+// newYAMLError converts an error of goccy/go-yaml to fragmentError to output the position.
+func newYAMLError(path string, err error) error {
+	var yErr yaml.Error
+	if errors.As(err, &yErr) {
+		if tk := yErr.GetToken(); tk != nil && tk.Position != nil {
+			return &fragmentError{Path: path, Line: tk.Position.Line, Message: yErr.GetMessage()}
+		}
+	}
+	return fmt.Errorf("parse %s as YAML: %w", path, err)
+}
+
+// sequenceValues returns values of the sequence `key` in the mapping node.
+// It returns nil if the sequence isn't found.
+func sequenceValues(node ast.Node, key string) []ast.Node {
+	mv, err := wast.FindMappingValueFromNode(node, key)
+	if err != nil || mv == nil {
+		return nil
+	}
+	seq, ok := mv.Value.(*ast.SequenceNode)
+	if !ok {
+		return nil
+	}
+	return seq.Values
+}
+
+// nodeLine returns the line of the idx-th node.
+// It returns 1 if the node isn't found.
+func nodeLine(nodes []ast.Node, idx int) int {
+	if idx >= len(nodes) {
+		return 1
+	}
+	tk := nodes[idx].GetToken()
+	if tk == nil || tk.Position == nil {
+		return 1
+	}
+	return tk.Position.Line
+}
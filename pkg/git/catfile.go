@@ -0,0 +1,68 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func (r *repo) CatFiles(ctx context.Context, rev string, paths []string) (map[string][]byte, error) {
+	args := []string{"cat-file", "--batch"}
+	cmd := r.command(ctx, args...)
+	stdin := &bytes.Buffer{}
+	for _, p := range paths {
+		stdin.WriteString(rev + ":" + p + "\n")
+	}
+	cmd.Stdin = stdin
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, &Error{Args: args, Stderr: stderr.String(), Err: err}
+	}
+	files, err := parseCatFileBatch(stdout, paths)
+	if err != nil {
+		return nil, fmt.Errorf("parse the output of git cat-file: %w", err)
+	}
+	return files, nil
+}
+
+// parseCatFileBatch parses the output of git cat-file --batch.
+// Each object is output as "<oid> <type> <size>\n<content>\n" in the order of paths,
+// and missing objects are output as "<object> missing\n".
+func parseCatFileBatch(out io.Reader, paths []string) (map[string][]byte, error) {
+	br := bufio.NewReader(out)
+	files := make(map[string][]byte, len(paths))
+	for _, p := range paths {
+		header, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("read the header of %s: %w", p, err)
+		}
+		header = strings.TrimSuffix(header, "\n")
+		if strings.HasSuffix(header, " missing") {
+			continue
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 { //nolint:mnd
+			return nil, fmt.Errorf("invalid header of %s: %s", p, header)
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("parse the size of %s: %w", p, err)
+		}
+		content := make([]byte, size+1) // the content is followed by a newline
+		if _, err := io.ReadFull(br, content); err != nil {
+			return nil, fmt.Errorf("read %s: %w", p, err)
+		}
+		if fields[1] != "blob" {
+			continue
+		}
+		files[p] = content[:size]
+	}
+	return files, nil
+}
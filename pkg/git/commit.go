@@ -0,0 +1,39 @@
+package git
+
+// CommitOptions is options of git commit.
+type CommitOptions struct {
+	// Message is the commit message.
+	// If it's empty, git opens an editor.
+	Message string
+	// SignOff adds a Signed-off-by trailer.
+	SignOff bool
+	// Sign signs the commit.
+	Sign bool
+	// SigningFormat is the format of the signature. openpgp, ssh, or x509.
+	SigningFormat string
+	// SigningKey is the key to sign the commit.
+	SigningKey string
+}
+
+// Args returns arguments of git to commit.
+func (o *CommitOptions) Args() []string {
+	var args []string
+	if o.SigningFormat != "" {
+		args = append(args, "-c", "gpg.format="+o.SigningFormat)
+	}
+	args = append(args, "commit")
+	if o.Message != "" {
+		args = append(args, "-m", o.Message)
+	}
+	if o.SignOff {
+		args = append(args, "--signoff")
+	}
+	if o.Sign || o.SigningKey != "" {
+		if o.SigningKey == "" {
+			args = append(args, "--gpg-sign")
+		} else {
+			args = append(args, "--gpg-sign="+o.SigningKey)
+		}
+	}
+	return args
+}
@@ -0,0 +1,288 @@
+// Package git runs git commands in a repository.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aquaproj/registry-tool/pkg/osexec"
+)
+
+// Repo is a git repository.
+type Repo interface {
+	// Dir returns the directory where git commands are run.
+	// An empty string means the current directory.
+	Dir() string
+
+	// CurrentBranch returns the current branch name.
+	CurrentBranch(ctx context.Context) (string, error)
+	// BranchExists returns true if the local branch exists.
+	BranchExists(ctx context.Context, branch string) (bool, error)
+	// Checkout switches to the branch.
+	Checkout(ctx context.Context, branch string) error
+	// CreateBranch creates the branch from startPoint and switches to it.
+	CreateBranch(ctx context.Context, branch, startPoint string) error
+
+	// Remotes returns names of remotes.
+	Remotes(ctx context.Context) ([]string, error)
+	// RemoteURL returns the URL of the remote.
+	RemoteURL(ctx context.Context, name string) (string, error)
+	// AddRemote adds a remote.
+	AddRemote(ctx context.Context, name, url string) error
+	// RemoveRemote removes a remote.
+	RemoveRemote(ctx context.Context, name string) error
+	// Fetch fetches refspecs from the remote.
+	Fetch(ctx context.Context, remote string, refspecs ...string) error
+	// Push pushes the branch to the remote.
+	Push(ctx context.Context, remote, branch string) error
+	// Merge merges the ref into the current branch.
+	Merge(ctx context.Context, ref string) error
+
+	// Status returns changes of paths.
+	Status(ctx context.Context, paths ...string) (*Status, error)
+	// Add stages paths.
+	Add(ctx context.Context, paths ...string) error
+	// Move moves a tracked file so that its history follows it.
+	Move(ctx context.Context, src, dst string) error
+	// Commit creates a commit.
+	Commit(ctx context.Context, opts *CommitOptions) error
+	// LsFiles returns tracked files under paths.
+	LsFiles(ctx context.Context, paths ...string) ([]string, error)
+	// GitPath returns the path of the file in the git directory such as .git/<path>.
+	GitPath(ctx context.Context, path string) (string, error)
+	// LogAdded returns commits adding the file from newest to oldest.
+	LogAdded(ctx context.Context, path string) ([]string, error)
+	// Show returns the content of the file at the revision.
+	Show(ctx context.Context, rev, path string) ([]byte, error)
+	// LsTree returns files under paths at the revision.
+	LsTree(ctx context.Context, rev string, paths ...string) ([]string, error)
+	// CatFiles returns contents of files at the revision in one git process.
+	// Files which don't exist at the revision are omitted.
+	CatFiles(ctx context.Context, rev string, paths []string) (map[string][]byte, error)
+	// DiffNames returns tracked files under paths changed in the working tree from the revision.
+	DiffNames(ctx context.Context, rev string, paths ...string) ([]string, error)
+}
+
+// Error is returned when a git command fails.
+// Stderr is kept so that callers can handle specific failures.
+type Error struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("git %s: %v", strings.Join(e.Args, " "), e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type repo struct {
+	logger *slog.Logger
+	dir    string
+}
+
+// New returns a Repo running git commands in dir.
+// If dir is empty, commands are run in the current directory.
+func New(logger *slog.Logger, dir string) Repo {
+	return &repo{
+		logger: logger,
+		dir:    dir,
+	}
+}
+
+func (r *repo) Dir() string {
+	return r.dir
+}
+
+func (r *repo) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	osexec.SetCancel(r.logger, cmd)
+	return cmd
+}
+
+// run runs a git command changing the repository.
+// The command is logged and its output is written to the standard output and the standard error output.
+func (r *repo) run(ctx context.Context, args ...string) error {
+	cmd := r.command(ctx, args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	r.logger.Info("+ " + cmd.String())
+	if err := cmd.Run(); err != nil {
+		return &Error{Args: args, Stderr: stderr.String(), Err: err}
+	}
+	return nil
+}
+
+// output runs a git command reading the repository and returns the standard output.
+func (r *repo) output(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := r.command(ctx, args...)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, &Error{Args: args, Stderr: stderr.String(), Err: err}
+	}
+	return stdout.Bytes(), nil
+}
+
+// succeeds runs a git command and returns false if it exits with a non-zero code.
+func (r *repo) succeeds(ctx context.Context, args ...string) (bool, error) {
+	if _, err := r.output(ctx, args...); err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *repo) CurrentBranch(ctx context.Context) (string, error) {
+	out, err := r.output(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *repo) BranchExists(ctx context.Context, branch string) (bool, error) {
+	return r.succeeds(ctx, "show-ref", "--quiet", "refs/heads/"+branch)
+}
+
+func (r *repo) Checkout(ctx context.Context, branch string) error {
+	return r.run(ctx, "checkout", branch)
+}
+
+func (r *repo) CreateBranch(ctx context.Context, branch, startPoint string) error {
+	return r.run(ctx, "checkout", "-b", branch, startPoint)
+}
+
+func (r *repo) Remotes(ctx context.Context) ([]string, error) {
+	out, err := r.output(ctx, "remote")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+func (r *repo) RemoteURL(ctx context.Context, name string) (string, error) {
+	out, err := r.output(ctx, "remote", "get-url", name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *repo) AddRemote(ctx context.Context, name, url string) error {
+	return r.run(ctx, "remote", "add", name, url)
+}
+
+func (r *repo) RemoveRemote(ctx context.Context, name string) error {
+	return r.run(ctx, "remote", "remove", name)
+}
+
+func (r *repo) Fetch(ctx context.Context, remote string, refspecs ...string) error {
+	return r.run(ctx, append([]string{"fetch", remote}, refspecs...)...)
+}
+
+func (r *repo) Push(ctx context.Context, remote, branch string) error {
+	return r.run(ctx, "push", remote, branch)
+}
+
+func (r *repo) Merge(ctx context.Context, ref string) error {
+	return r.run(ctx, "merge", ref)
+}
+
+func (r *repo) Add(ctx context.Context, paths ...string) error {
+	return r.run(ctx, append([]string{"add", "--"}, paths...)...)
+}
+
+func (r *repo) Move(ctx context.Context, src, dst string) error {
+	return r.run(ctx, "mv", "--", src, dst)
+}
+
+func (r *repo) Commit(ctx context.Context, opts *CommitOptions) error {
+	if opts.Message != "" {
+		return r.run(ctx, opts.Args()...)
+	}
+	// Let users edit the commit message
+	cmd := r.command(ctx, opts.Args()...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	r.logger.Info("+ " + cmd.String())
+	if err := cmd.Run(); err != nil {
+		return &Error{Args: opts.Args(), Err: err}
+	}
+	return nil
+}
+
+func (r *repo) LsFiles(ctx context.Context, paths ...string) ([]string, error) {
+	out, err := r.output(ctx, append([]string{"ls-files", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+func (r *repo) GitPath(ctx context.Context, path string) (string, error) {
+	out, err := r.output(ctx, "rev-parse", "--git-path", path)
+	if err != nil {
+		return "", err
+	}
+	p := strings.TrimSpace(string(out))
+	if filepath.IsAbs(p) {
+		return p, nil
+	}
+	return filepath.Join(r.dir, p), nil
+}
+
+func (r *repo) LogAdded(ctx context.Context, path string) ([]string, error) {
+	out, err := r.output(ctx, "log", "--diff-filter=A", "--format=%H", "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+func (r *repo) Show(ctx context.Context, rev, path string) ([]byte, error) {
+	return r.output(ctx, "show", rev+":"+path)
+}
+
+func (r *repo) LsTree(ctx context.Context, rev string, paths ...string) ([]string, error) {
+	out, err := r.output(ctx, append([]string{"ls-tree", "-r", "-z", "--name-only", rev, "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+func (r *repo) DiffNames(ctx context.Context, rev string, paths ...string) ([]string, error) {
+	out, err := r.output(ctx, append([]string{"diff", "--name-only", "-z", rev, "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+func splitNUL(b []byte) []string {
+	s := strings.TrimRight(string(b), "\x00")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\x00")
+}
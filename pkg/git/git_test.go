@@ -0,0 +1,190 @@
+package git_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newRepo creates a repository having a commit on the branch main.
+func newRepo(t *testing.T) (git.Repo, string) {
+	t.Helper()
+	testutil.SetGitEnv(t)
+	dir := t.TempDir()
+	testutil.Git(t, dir, "init", "-b", "main")
+	testutil.WriteFile(t, filepath.Join(dir, "pkgs", "foo", "bar", "registry.yaml"), "packages: []\n")
+	testutil.Git(t, dir, "add", ".")
+	testutil.Git(t, dir, "commit", "-m", "init")
+	return git.New(slog.New(slog.DiscardHandler), dir), dir
+}
+
+func TestRepo_Branches(t *testing.T) {
+	repo, _ := newRepo(t)
+	ctx := context.Background()
+	if err := repo.CreateBranch(ctx, "feat/foo", "main"); err != nil {
+		t.Fatal(err)
+	}
+	branch, err := repo.CurrentBranch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "feat/foo" {
+		t.Fatalf("wanted feat/foo, got %s", branch)
+	}
+	for name, exp := range map[string]bool{"main": true, "feat/foo": true, "feat/bar": false} {
+		exist, err := repo.BranchExists(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exist != exp {
+			t.Fatalf("branch %s: wanted %v, got %v", name, exp, exist)
+		}
+	}
+	if err := repo.Checkout(ctx, "main"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepo_Status(t *testing.T) {
+	repo, dir := newRepo(t)
+	ctx := context.Background()
+	st, err := repo.Status(ctx, "pkgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Clean() {
+		t.Fatalf("pkgs must be clean: %+v", st)
+	}
+	testutil.WriteFile(t, filepath.Join(dir, "pkgs", "foo", "bar", "registry.yaml"), "packages: [{}]\n")
+	testutil.WriteFile(t, filepath.Join(dir, "pkgs", "foo", "baz", "registry.yaml"), "packages: []\n")
+	testutil.WriteFile(t, filepath.Join(dir, "pkgs", "foo", "qux", "registry.yaml"), "packages: []\n")
+	testutil.WriteFile(t, filepath.Join(dir, "README.md"), "")
+	if err := repo.Add(ctx, "pkgs/foo/qux"); err != nil {
+		t.Fatal(err)
+	}
+	st, err = repo.Status(ctx, "pkgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &git.Status{
+		Staged:    []string{"pkgs/foo/qux/registry.yaml"},
+		Unstaged:  []string{"pkgs/foo/bar/registry.yaml"},
+		Untracked: []string{"pkgs/foo/baz/registry.yaml"},
+	}
+	if diff := cmp.Diff(exp, st); diff != "" {
+		t.Fatalf("status (-want +got):\n%s", diff)
+	}
+}
+
+func TestRepo_CommitAndLog(t *testing.T) {
+	repo, dir := newRepo(t)
+	ctx := context.Background()
+	path := "pkgs/foo/bar/registry.yaml"
+	testutil.WriteFile(t, filepath.Join(dir, filepath.FromSlash(path)), "packages: [{}]\n")
+	if err := repo.Add(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit(ctx, &git.CommitOptions{Message: "update", SignOff: true}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := testutil.Git(t, dir, "log", "-1", "--format=%B"); !strings.Contains(msg, "Signed-off-by: test <test@example.com>") {
+		t.Fatalf("commit must be signed off: %s", msg)
+	}
+	commits, err := repo.LogAdded(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("wanted 1 commit, got %v", commits)
+	}
+	b, err := repo.Show(ctx, commits[0], path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "packages: []\n" {
+		t.Fatalf("unexpected content: %s", b)
+	}
+	files, err := repo.LsFiles(ctx, "pkgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{path}, files); diff != "" {
+		t.Fatalf("files (-want +got):\n%s", diff)
+	}
+}
+
+func TestRepo_Remotes(t *testing.T) {
+	repo, dir := newRepo(t)
+	ctx := context.Background()
+	bare := filepath.Join(t.TempDir(), "origin.git")
+	testutil.Git(t, dir, "init", "--bare", bare)
+	if err := repo.AddRemote(ctx, "origin", bare); err != nil {
+		t.Fatal(err)
+	}
+	remotes, err := repo.Remotes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"origin"}, remotes); diff != "" {
+		t.Fatalf("remotes (-want +got):\n%s", diff)
+	}
+	url, err := repo.RemoteURL(ctx, "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != bare {
+		t.Fatalf("wanted %s, got %s", bare, url)
+	}
+	if err := repo.Push(ctx, "origin", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Fetch(ctx, "origin", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Merge(ctx, "origin/main"); err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Push(ctx, "not-found", "main")
+	gitErr := &git.Error{}
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("wanted *git.Error, got %v", err)
+	}
+	if gitErr.Stderr == "" {
+		t.Fatal("stderr must be kept")
+	}
+	if err := repo.RemoveRemote(ctx, "origin"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepo_CatFiles(t *testing.T) {
+	repo, dir := newRepo(t)
+	ctx := context.Background()
+	testutil.WriteFile(t, filepath.Join(dir, "pkgs", "cli", "cli", "registry.yaml"), "packages:\n  - repo_owner: cli\n")
+	testutil.Git(t, dir, "add", ".")
+	testutil.Git(t, dir, "commit", "-m", "add cli/cli")
+	files, err := repo.LsTree(ctx, "HEAD", "pkgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"pkgs/cli/cli/registry.yaml", "pkgs/foo/bar/registry.yaml"}, files); diff != "" {
+		t.Fatalf("files (-want +got):\n%s", diff)
+	}
+	contents, err := repo.CatFiles(ctx, "HEAD~1", append(files, "pkgs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string][]byte{
+		"pkgs/foo/bar/registry.yaml": []byte("packages: []\n"),
+	}
+	if diff := cmp.Diff(exp, contents); diff != "" {
+		t.Fatalf("contents (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"context"
+)
+
+// Status is changes of files in the working tree and the index.
+type Status struct {
+	// Staged is files having changes in the index.
+	Staged []string
+	// Unstaged is tracked files having changes in the working tree.
+	Unstaged []string
+	// Untracked is files not tracked and not ignored.
+	Untracked []string
+}
+
+// Clean returns true if there are no changes.
+func (s *Status) Clean() bool {
+	return len(s.Staged) == 0 && len(s.Unstaged) == 0 && len(s.Untracked) == 0
+}
+
+func (r *repo) Status(ctx context.Context, paths ...string) (*Status, error) {
+	out, err := r.output(ctx, append([]string{"status", "--porcelain=v1", "-z", "--untracked-files=all", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(out), nil
+}
+
+// parseStatus parses the output of `git status --porcelain=v1 -z`.
+// Each entry is "XY path", where X is the status of the index and Y is the status of the working tree.
+// Renamed and copied entries are followed by the original path.
+func parseStatus(b []byte) *Status {
+	st := &Status{}
+	entries := splitNUL(b)
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 { //nolint:mnd
+			continue
+		}
+		x, y, path := entry[0], entry[1], entry[3:]
+		if x == 'R' || x == 'C' {
+			// Skip the original path
+			i++
+		}
+		if x == '?' {
+			st.Untracked = append(st.Untracked, path)
+			continue
+		}
+		if x != ' ' {
+			st.Staged = append(st.Staged, path)
+		}
+		if y != ' ' {
+			st.Unstaged = append(st.Unstaged, path)
+		}
+	}
+	return st
+}
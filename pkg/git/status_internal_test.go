@@ -0,0 +1,20 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+	out := "M  pkgs/a/registry.yaml\x00 M pkgs/b/registry.yaml\x00MM pkgs/c/pkg.yaml\x00R  pkgs/d/new.yaml\x00pkgs/d/old.yaml\x00?? pkgs/e/registry.yaml\x00"
+	exp := &Status{
+		Staged:    []string{"pkgs/a/registry.yaml", "pkgs/c/pkg.yaml", "pkgs/d/new.yaml"},
+		Unstaged:  []string{"pkgs/b/registry.yaml", "pkgs/c/pkg.yaml"},
+		Untracked: []string{"pkgs/e/registry.yaml"},
+	}
+	if diff := cmp.Diff(exp, parseStatus([]byte(out))); diff != "" {
+		t.Fatalf("status (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	cacheDirPermission  os.FileMode = 0o700
+	cacheFilePermission os.FileMode = 0o600
+)
+
+// cacheEntry is a cached response of GitHub REST API.
+type cacheEntry struct {
+	URL    string      `json:"url"`
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// diskCache caches responses on disk by URL.
+// Cached responses are revalidated by conditional requests with ETag.
+type diskCache struct {
+	dir string
+}
+
+func (c *diskCache) path(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the cached response of the URL.
+// It returns nil if the response isn't cached.
+func (c *diskCache) get(u string) (*cacheEntry, error) {
+	b, err := os.ReadFile(c.path(u))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil //nolint:nilnil
+		}
+		return nil, fmt.Errorf("read a cache file: %w", err)
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, fmt.Errorf("parse a cache file as JSON: %w", err)
+	}
+	if entry.URL != u {
+		return nil, nil //nolint:nilnil
+	}
+	return entry, nil
+}
+
+func (c *diskCache) set(entry *cacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode a cache as JSON: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, cacheDirPermission); err != nil {
+		return fmt.Errorf("create a cache directory: %w", err)
+	}
+	// Write a temporary file and rename it so that concurrent processes don't read a partial file
+	f, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("create a temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("write a cache file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close a cache file: %w", err)
+	}
+	if err := os.Chmod(f.Name(), cacheFilePermission); err != nil {
+		return fmt.Errorf("change the permission of a cache file: %w", err)
+	}
+	if err := os.Rename(f.Name(), c.path(entry.URL)); err != nil {
+		return fmt.Errorf("rename a cache file: %w", err)
+	}
+	return nil
+}
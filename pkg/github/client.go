@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/google/go-github/v89/github"
+)
+
+type (
+	ListOptions       = github.ListOptions
+	ReleaseAsset      = github.ReleaseAsset
+	RepositoryRelease = github.RepositoryRelease
+	RepositoryTag     = github.RepositoryTag
+	Response          = github.Response
+)
+
+// Client is a client of GitHub REST API.
+type Client struct {
+	*github.RepositoriesService
+
+	transport *Transport
+}
+
+// New returns a client of GitHub REST API.
+// A GitHub access token is resolved by ResolveAccessToken.
+func New(ctx context.Context, logger *slog.Logger, cfg *config.GitHub) (*Client, error) {
+	token, err := ResolveAccessToken(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	value := ""
+	if token != nil {
+		value = token.Value
+	}
+	transport := NewTransport(logger, nil, cfg.CacheDir, value)
+	baseURL := strings.TrimSuffix(cfg.APIBaseURL, "/") + "/"
+	client, err := github.NewClient(
+		github.WithHTTPClient(&http.Client{Transport: transport}),
+		github.WithURLs(&baseURL, nil),
+		// Transport waits for the rate limit
+		github.WithDisableRateLimitCheck(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create a GitHub client: %w", err)
+	}
+	return &Client{
+		RepositoriesService: client.Repositories,
+		transport:           transport,
+	}, nil
+}
+
+// LogStats outputs the number of requests and cache hits at the debug level.
+func (c *Client) LogStats() {
+	c.transport.LogStats()
+}
@@ -0,0 +1,162 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/suzuki-shunsuke/slog-error/slogerr"
+)
+
+// RateLimit is the rate limit of GitHub REST API for the token.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	// TokenType is the type of the token guessed by the prefix.
+	TokenType string
+	// Scopes is OAuth scopes of the token. Only classic personal access tokens and OAuth tokens have scopes.
+	Scopes []string
+}
+
+// StatusError is returned when GitHub API returns an unexpected status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// ErrTokenNotFound is returned by Preflight when a token is required but isn't found.
+var ErrTokenNotFound = errors.New("GitHub access token isn't found. Set the environment variable GITHUB_TOKEN, log in by `gh auth login`, or set a token to the keyring by `aqua token set`")
+
+// tokenType returns the type of the token by the prefix.
+// https://github.blog/engineering/platform-security/behind-githubs-new-authentication-token-formats/
+func tokenType(token string) string {
+	for _, t := range []struct {
+		prefix string
+		name   string
+	}{
+		{"github_pat_", "fine-grained personal access token"},
+		{"ghp_", "personal access token (classic)"},
+		{"gho_", "OAuth access token"},
+		{"ghu_", "GitHub App user access token"},
+		{"ghs_", "GitHub App installation access token"},
+	} {
+		if strings.HasPrefix(token, t.prefix) {
+			return t.name
+		}
+	}
+	if token == "" {
+		return "none"
+	}
+	return "unknown"
+}
+
+// GetRateLimit gets the rate limit of the core API by GET /rate_limit.
+// The request doesn't consume the rate limit.
+func GetRateLimit(ctx context.Context, client *http.Client, baseURL, token string) (*RateLimit, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/rate_limit", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create a request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send a request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+	body := &struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(body); err != nil {
+		return nil, fmt.Errorf("decode the response body as JSON: %w", err)
+	}
+	rl := &RateLimit{
+		Limit:     body.Resources.Core.Limit,
+		Remaining: body.Resources.Core.Remaining,
+		Reset:     time.Unix(body.Resources.Core.Reset, 0),
+		TokenType: tokenType(token),
+	}
+	if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" {
+		for scope := range strings.SplitSeq(scopes, ",") {
+			rl.Scopes = append(rl.Scopes, strings.TrimSpace(scope))
+		}
+	}
+	return rl, nil
+}
+
+// Preflight checks the token and the rate limit before calling GitHub API many times.
+// If no token is found, it returns ErrTokenNotFound when requireToken is true, otherwise it outputs a warning.
+// Failures of the rate limit API are outputted as warnings except for invalid tokens.
+func Preflight(ctx context.Context, logger *slog.Logger, client *http.Client, baseURL string, token *Token, requireToken bool) error {
+	value := ""
+	if token == nil || token.Value == "" {
+		if requireToken {
+			return ErrTokenNotFound
+		}
+		logger.Warn("GitHub access token isn't found, so GitHub API may be rate limited. Set the environment variable GITHUB_TOKEN or log in by `gh auth login`")
+	} else {
+		value = token.Value
+		logger = logger.With("token_source", token.Source)
+	}
+	rl, err := GetRateLimit(ctx, client, baseURL, value)
+	if err != nil {
+		statusErr := &StatusError{}
+		if value != "" && errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized {
+			return slogerr.With(fmt.Errorf("the GitHub access token is invalid: %w", err), "token_source", token.Source) //nolint:wrapcheck
+		}
+		slogerr.WithError(logger, err).Warn("failed to get the rate limit of GitHub API")
+		return nil
+	}
+	logger = logger.With(
+		"token_type", rl.TokenType,
+		"remaining", rl.Remaining,
+		"limit", rl.Limit,
+		"reset", rl.Reset.Format(time.RFC3339),
+	)
+	if len(rl.Scopes) > 0 {
+		logger = logger.With("scopes", strings.Join(rl.Scopes, ","))
+	}
+	if rl.Remaining == 0 {
+		logger.Warn("GitHub API rate limit is exceeded")
+		return nil
+	}
+	logger.Info("GitHub API rate limit")
+	return nil
+}
+
+// PrepareAccessToken resolves the GitHub token and runs Preflight.
+// It returns an empty string if no token is found and requireToken is false.
+func PrepareAccessToken(ctx context.Context, logger *slog.Logger, cfg *config.GitHub, requireToken bool) (string, error) {
+	token, err := ResolveAccessToken(ctx, logger)
+	if err != nil {
+		return "", err
+	}
+	if err := Preflight(ctx, logger, http.DefaultClient, cfg.APIBaseURL, token, requireToken); err != nil {
+		return "", err
+	}
+	if token == nil {
+		return "", nil
+	}
+	return token.Value, nil
+}
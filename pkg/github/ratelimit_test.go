@@ -0,0 +1,76 @@
+package github_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newRateLimitServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rate_limit" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Header.Get("Authorization") {
+		case "":
+			_, _ = w.Write([]byte(`{"resources":{"core":{"limit":60,"remaining":0,"reset":1700000000}}}`))
+		case "Bearer ghp_valid":
+			w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+			_, _ = w.Write([]byte(`{"resources":{"core":{"limit":5000,"remaining":4999,"reset":1700000000}}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetRateLimit(t *testing.T) {
+	t.Parallel()
+	srv := newRateLimitServer(t)
+	rl, err := github.GetRateLimit(t.Context(), srv.Client(), srv.URL, "ghp_valid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &github.RateLimit{
+		Limit:     5000,
+		Remaining: 4999,
+		Reset:     rl.Reset,
+		TokenType: "personal access token (classic)",
+		Scopes:    []string{"repo", "read:org"},
+	}
+	if diff := cmp.Diff(exp, rl); diff != "" {
+		t.Fatalf("rate limit (-want +got):\n%s", diff)
+	}
+	if rl.Reset.Unix() != 1700000000 {
+		t.Fatalf("unexpected reset: %v", rl.Reset)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	t.Parallel()
+	srv := newRateLimitServer(t)
+	logger := slog.New(slog.DiscardHandler)
+	ctx := t.Context()
+	if err := github.Preflight(ctx, logger, srv.Client(), srv.URL, &github.Token{Value: "ghp_valid", Source: github.SourceGitHubToken}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := github.Preflight(ctx, logger, srv.Client(), srv.URL, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := github.Preflight(ctx, logger, srv.Client(), srv.URL, nil, true); !errors.Is(err, github.ErrTokenNotFound) {
+		t.Fatalf("wanted ErrTokenNotFound, got %v", err)
+	}
+	err := github.Preflight(ctx, logger, srv.Client(), srv.URL, &github.Token{Value: "ghp_invalid", Source: github.SourceGH}, false)
+	statusErr := &github.StatusError{}
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wanted 401, got %v", err)
+	}
+}
@@ -2,32 +2,141 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
+	"strconv"
+	"strings"
 
+	"github.com/aquaproj/registry-tool/pkg/osexec"
 	"github.com/suzuki-shunsuke/ghtkn-go-sdk/ghtkn"
+	"github.com/suzuki-shunsuke/urfave-cli-v3-util/keyring/ghtoken"
+	"github.com/zalando/go-keyring"
 )
 
-// GetAccessToken retrieves the GitHub token from environment or gh CLI.
+// Sources of GitHub access tokens.
+const (
+	SourceAquaGitHubToken = "AQUA_GITHUB_TOKEN"
+	SourceGitHubToken     = "GITHUB_TOKEN"
+	SourceGhtkn           = "ghtkn"
+	SourceGH              = "gh auth token"
+	SourceKeyring         = "keyring"
+)
+
+// keyringService is the service name of the keyring shared with aqua.
+// Tokens are set by `aqua token set`.
+const keyringService = "aquaproj.github.io"
+
+// Token is a GitHub access token and where it came from.
+type Token struct {
+	Value  string
+	Source string
+}
+
+// GetAccessToken retrieves the GitHub token.
+// It returns an empty string if no token is found.
 func GetAccessToken(ctx context.Context, logger *slog.Logger) (string, error) {
-	if token := os.Getenv("AQUA_GITHUB_TOKEN"); token != "" {
-		return token, nil
+	token, err := ResolveAccessToken(ctx, logger)
+	if err != nil {
+		return "", err
+	}
+	if token == nil {
+		return "", nil
+	}
+	return token.Value, nil
+}
+
+// ResolveAccessToken retrieves the GitHub token and its source.
+// Sources are tried in the following order, and it returns nil if no token is found.
+//
+//  1. the environment variable AQUA_GITHUB_TOKEN
+//  2. the environment variable GITHUB_TOKEN
+//  3. ghtkn if AQUA_GHTKN_ENABLED is true
+//  4. `gh auth token` if gh is installed
+//  5. the OS keyring if AQUA_KEYRING_ENABLED is true
+func ResolveAccessToken(ctx context.Context, logger *slog.Logger) (*Token, error) {
+	return newTokenResolver(logger).resolve(ctx, logger)
+}
+
+// tokenResolver resolves GitHub access tokens.
+// Each source returns an empty string if no token is found.
+type tokenResolver struct {
+	getenv       func(string) string
+	ghtknEnabled func() (bool, error)
+	ghtkn        func(ctx context.Context, logger *slog.Logger) (string, error)
+	gh           func(ctx context.Context) (string, error)
+	keyring      func() (string, error)
+}
+
+func newTokenResolver(logger *slog.Logger) *tokenResolver {
+	return &tokenResolver{
+		getenv:       os.Getenv,
+		ghtknEnabled: ghtknEnabled,
+		ghtkn:        getTokenByGhtkn,
+		gh:           func(ctx context.Context) (string, error) { return getTokenByGH(ctx, logger) },
+		keyring:      func() (string, error) { return getTokenFromKeyring(logger) },
+	}
+}
+
+func (r *tokenResolver) resolve(ctx context.Context, logger *slog.Logger) (*Token, error) {
+	for _, env := range []string{SourceAquaGitHubToken, SourceGitHubToken} {
+		if token := r.getenv(env); token != "" {
+			return &Token{Value: token, Source: env}, nil
+		}
 	}
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		return token, nil
+	if enabled, err := r.ghtknEnabled(); err != nil {
+		return nil, fmt.Errorf("check ghtkn enabled: %w", err)
+	} else if enabled {
+		token, err := r.ghtkn(ctx, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &Token{Value: token, Source: SourceGhtkn}, nil
 	}
-	ghtknEnabled, err := ghtkn.Enabled(&ghtkn.InputEnabled{
+	token, err := r.gh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		return &Token{Value: token, Source: SourceGH}, nil
+	}
+	if enabled, err := r.envEnabled("AQUA_KEYRING_ENABLED"); err != nil {
+		return nil, err
+	} else if enabled {
+		token, err := r.keyring()
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			return &Token{Value: token, Source: SourceKeyring}, nil
+		}
+	}
+	return nil, nil //nolint:nilnil
+}
+
+func (r *tokenResolver) envEnabled(name string) (bool, error) {
+	v := r.getenv(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("parse the environment variable %s as bool: %w", name, err)
+	}
+	return b, nil
+}
+
+func ghtknEnabled() (bool, error) {
+	return ghtkn.Enabled(&ghtkn.InputEnabled{ //nolint:wrapcheck
 		Envs: []string{
 			"AQUA_GHTKN_ENABLED",
 		},
 	})
-	if err != nil {
-		return "", fmt.Errorf("check ghtkn enabled: %w", err)
-	}
-	if !ghtknEnabled {
-		return "", nil
-	}
+}
+
+func getTokenByGhtkn(ctx context.Context, logger *slog.Logger) (string, error) {
 	client, err := ghtkn.New()
 	if err != nil {
 		return "", fmt.Errorf("create ghtkn client: %w", err)
@@ -38,3 +147,32 @@ func GetAccessToken(ctx context.Context, logger *slog.Logger) (string, error) {
 	}
 	return token.AccessToken, nil
 }
+
+// getTokenByGH gets a token by `gh auth token`.
+// It returns an empty string if gh isn't installed or isn't logged in.
+func getTokenByGH(ctx context.Context, logger *slog.Logger) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", nil
+	}
+	cmd := exec.CommandContext(ctx, "gh", "auth", "token")
+	osexec.SetCancel(logger, cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		logger.Debug("failed to get a GitHub access token by gh", "error", err)
+		return "", nil
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// getTokenFromKeyring gets a token from the OS keyring.
+// It returns an empty string if the token isn't set.
+func getTokenFromKeyring(logger *slog.Logger) (string, error) {
+	token, err := ghtoken.NewTokenSource(logger, keyringService).Token()
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get a GitHub access token from the keyring: %w", err)
+	}
+	return token.AccessToken, nil
+}
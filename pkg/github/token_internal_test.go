@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTokenResolver_resolve(t *testing.T) { //nolint:funlen
+	t.Parallel()
+	data := []struct {
+		name    string
+		envs    map[string]string
+		ghtkn   bool
+		gh      string
+		keyring string
+		exp     *Token
+	}{
+		{
+			name: "AQUA_GITHUB_TOKEN",
+			envs: map[string]string{"AQUA_GITHUB_TOKEN": "aqua", "GITHUB_TOKEN": "github"},
+			gh:   "gh",
+			exp:  &Token{Value: "aqua", Source: SourceAquaGitHubToken},
+		},
+		{
+			name: "GITHUB_TOKEN",
+			envs: map[string]string{"GITHUB_TOKEN": "github"},
+			gh:   "gh",
+			exp:  &Token{Value: "github", Source: SourceGitHubToken},
+		},
+		{
+			name:  "ghtkn",
+			ghtkn: true,
+			gh:    "gh",
+			exp:   &Token{Value: "ghtkn", Source: SourceGhtkn},
+		},
+		{
+			name:    "gh",
+			envs:    map[string]string{"AQUA_KEYRING_ENABLED": "true"},
+			gh:      "gh",
+			keyring: "keyring",
+			exp:     &Token{Value: "gh", Source: SourceGH},
+		},
+		{
+			name:    "keyring",
+			envs:    map[string]string{"AQUA_KEYRING_ENABLED": "true"},
+			keyring: "keyring",
+			exp:     &Token{Value: "keyring", Source: SourceKeyring},
+		},
+		{
+			name:    "keyring is disabled",
+			keyring: "keyring",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			r := &tokenResolver{
+				getenv: func(k string) string {
+					return d.envs[k]
+				},
+				ghtknEnabled: func() (bool, error) {
+					return d.ghtkn, nil
+				},
+				ghtkn: func(context.Context, *slog.Logger) (string, error) {
+					return "ghtkn", nil
+				},
+				gh: func(context.Context) (string, error) {
+					return d.gh, nil
+				},
+				keyring: func() (string, error) {
+					return d.keyring, nil
+				},
+			}
+			token, err := r.resolve(t.Context(), slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(d.exp, token); diff != "" {
+				t.Fatalf("token (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
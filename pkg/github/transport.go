@@ -0,0 +1,249 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/suzuki-shunsuke/slog-error/slogerr"
+)
+
+const (
+	defaultMaxRetries = 3
+	// defaultMaxWait is the longest time to wait for the rate limit.
+	// If the rate limit is reset later, the response is returned as is.
+	defaultMaxWait = time.Minute
+)
+
+// Transport is a http.RoundTripper for GitHub.
+// It caches GET responses having ETag on disk and revalidates them by conditional requests,
+// and waits and retries requests when GitHub returns 403 or 429 by rate limits.
+type Transport struct {
+	base       http.RoundTripper
+	logger     *slog.Logger
+	cache      *diskCache
+	token      string
+	maxRetries int
+	maxWait    time.Duration
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	requests  atomic.Int64
+	cacheHits atomic.Int64
+}
+
+// NewTransport returns a Transport.
+// If cacheDir is empty, responses aren't cached.
+// If token is empty, requests aren't authenticated.
+func NewTransport(logger *slog.Logger, base http.RoundTripper, cacheDir, token string) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{
+		base:       base,
+		logger:     logger,
+		token:      token,
+		maxRetries: defaultMaxRetries,
+		maxWait:    defaultMaxWait,
+		now:        time.Now,
+		sleep:      sleep,
+	}
+	if cacheDir != "" {
+		t.cache = &diskCache{dir: cacheDir}
+	}
+	return t
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Requests returns the number of requests sent to the server.
+func (t *Transport) Requests() int64 {
+	return t.requests.Load()
+}
+
+// CacheHits returns the number of responses served from the cache.
+func (t *Transport) CacheHits() int64 {
+	return t.cacheHits.Load()
+}
+
+// LogStats outputs the number of requests and cache hits at the debug level.
+func (t *Transport) LogStats() {
+	t.logger.Debug("GitHub API requests", "requests", t.Requests(), "cache_hits", t.CacheHits())
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.token != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	cacheable := t.cache != nil && req.Method == http.MethodGet
+	var entry *cacheEntry
+	if cacheable {
+		e, err := t.cache.get(req.URL.String())
+		if err != nil {
+			slogerr.WithError(t.logger, err).Warn("failed to read the cache of GitHub API", "url", req.URL.String())
+		}
+		if e != nil {
+			entry = e
+			req.Header.Set("If-None-Match", e.ETag)
+		}
+	}
+	resp, err := t.roundTripWithRetry(req)
+	if err != nil {
+		return nil, err
+	}
+	if !cacheable {
+		return resp, nil
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		t.cacheHits.Add(1)
+		resp.Body.Close()
+		return cachedResponse(req, resp, entry), nil
+	}
+	if resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "" {
+		return t.store(req, resp)
+	}
+	return resp, nil
+}
+
+// cachedResponse returns the cached response.
+// Rate limit headers are taken from the response of the conditional request.
+func cachedResponse(req *http.Request, resp *http.Response, entry *cacheEntry) *http.Response {
+	header := entry.Header.Clone()
+	for k, v := range resp.Header {
+		if strings.HasPrefix(k, "X-Ratelimit-") {
+			header[k] = v
+		}
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+func (t *Transport) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err := t.cache.set(&cacheEntry{
+		URL:    req.URL.String(),
+		ETag:   resp.Header.Get("ETag"),
+		Header: resp.Header,
+		Body:   body,
+	}); err != nil {
+		slogerr.WithError(t.logger, err).Warn("failed to cache the response of GitHub API", "url", req.URL.String())
+	}
+	return resp, nil
+}
+
+func (t *Transport) roundTripWithRetry(req *http.Request) (*http.Response, error) {
+	// Requests whose body can't be replayed aren't retried
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+			req.Body = body
+		}
+		t.requests.Add(1)
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		if !replayable || attempt >= t.maxRetries {
+			return resp, nil
+		}
+		wait, ok := t.retryAfter(resp, attempt)
+		if !ok {
+			return resp, nil
+		}
+		t.logger.Warn("GitHub API is rate limited, so the request will be retried",
+			"url", req.URL.String(), "status_code", resp.StatusCode, "wait", wait.String())
+		// Drain the body to reuse the connection
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter returns how long to wait before retrying the request.
+// It returns false if the request shouldn't be retried.
+// https://docs.github.com/en/rest/using-the-rest-api/best-practices-for-using-the-rest-api#handle-rate-limit-errors-appropriately
+func (t *Transport) retryAfter(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	wait, ok := t.waitFromHeader(resp.Header)
+	if !ok {
+		if resp.StatusCode == http.StatusForbidden && !isSecondaryRateLimit(resp) {
+			// 403 without rate limit headers is a permission error
+			return 0, false
+		}
+		// Wait exponentially: 1s, 2s, 4s, ...
+		wait = time.Second << attempt
+	}
+	if wait > t.maxWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+func (t *Transport) waitFromHeader(header http.Header) (time.Duration, bool) {
+	if s := header.Get("Retry-After"); s != "" {
+		if sec, err := strconv.Atoi(s); err == nil {
+			return time.Duration(sec) * time.Second, true
+		}
+		if at, err := http.ParseTime(s); err == nil {
+			return max(at.Sub(t.now()), 0), true
+		}
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(t.now()), 0) + time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// isSecondaryRateLimit returns true if the body of the response mentions the rate limit.
+// The body is restored so that callers can read it.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16)) //nolint:mnd
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte("rate limit"))
+}
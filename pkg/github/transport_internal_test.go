@@ -0,0 +1,162 @@
+package github
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestTransport(t *testing.T, cacheDir string) (*Transport, *[]time.Duration) {
+	t.Helper()
+	waits := new([]time.Duration)
+	tr := NewTransport(slog.New(slog.DiscardHandler), nil, cacheDir, "xxx")
+	tr.now = func() time.Time {
+		return time.Unix(1700000000, 0)
+	}
+	tr.sleep = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return tr, waits
+}
+
+func get(t *testing.T, tr *Transport, u string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: tr}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(b)
+}
+
+func TestTransport_cache(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xxx" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	t.Cleanup(srv.Close)
+	cacheDir := t.TempDir()
+	tr, _ := newTestTransport(t, cacheDir)
+	for range 2 {
+		code, body := get(t, tr, srv.URL+"/repos/foo/bar/releases")
+		if code != http.StatusOK || body != `{"id":1}` {
+			t.Fatalf("unexpected response: %d %s", code, body)
+		}
+	}
+	if tr.Requests() != 2 || tr.CacheHits() != 1 {
+		t.Fatalf("requests: %d, cache hits: %d", tr.Requests(), tr.CacheHits())
+	}
+
+	// The cache is shared across processes
+	tr2, _ := newTestTransport(t, cacheDir)
+	if code, body := get(t, tr2, srv.URL+"/repos/foo/bar/releases"); code != http.StatusOK || body != `{"id":1}` {
+		t.Fatalf("unexpected response: %d %s", code, body)
+	}
+	if tr2.CacheHits() != 1 {
+		t.Fatalf("cache hits: %d", tr2.CacheHits())
+	}
+}
+
+func TestTransport_retry(t *testing.T) { //nolint:funlen
+	t.Parallel()
+	data := []struct {
+		name   string
+		fail   func(w http.ResponseWriter)
+		status int
+		waits  []time.Duration
+	}{
+		{
+			name: "429 with Retry-After",
+			fail: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			status: http.StatusOK,
+			waits:  []time.Duration{3 * time.Second},
+		},
+		{
+			name: "primary rate limit",
+			fail: func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.Itoa(1700000010))
+				w.WriteHeader(http.StatusForbidden)
+			},
+			status: http.StatusOK,
+			waits:  []time.Duration{11 * time.Second},
+		},
+		{
+			name: "secondary rate limit without headers",
+			fail: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+			},
+			status: http.StatusOK,
+			waits:  []time.Duration{time.Second},
+		},
+		{
+			name: "permission error",
+			fail: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name: "rate limit is reset too late",
+			fail: func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.Itoa(1700003600))
+				w.WriteHeader(http.StatusForbidden)
+			},
+			status: http.StatusForbidden,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			var count atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if count.Add(1) == 1 {
+					d.fail(w)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}))
+			t.Cleanup(srv.Close)
+			tr, waits := newTestTransport(t, "")
+			code, _ := get(t, tr, srv.URL)
+			if code != d.status {
+				t.Fatalf("wanted %d, got %d", d.status, code)
+			}
+			if diff := cmp.Diff(d.waits, *waits); diff != "" {
+				t.Fatalf("waits (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Package lint checks pkgs/**/registry.yaml with rules and fixes violations.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/suzuki-shunsuke/go-yamledit/yamledit"
+)
+
+// Severity is the severity of a rule.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Rule checks a registry.yaml.
+type Rule interface {
+	// ID is the unique identifier of the rule in kebab case.
+	ID() string
+	// Severity is the severity of violations of the rule.
+	Severity() Severity
+	// Doc describes what the rule checks and why.
+	Doc() string
+	// Check returns violations of the rule in the file.
+	Check(f *File) []*Violation
+}
+
+// Violation is a violation of a rule.
+type Violation struct {
+	// YAMLPath is the path to the node violating the rule. e.g. $.packages[0].files[0].name
+	// The position of the node is reported.
+	YAMLPath string
+	Message  string
+	// Fix fixes the violation. If it's nil, the violation can't be fixed automatically.
+	Fix yamledit.Action
+}
+
+// File is a parsed pkgs/<package name>/registry.yaml.
+type File struct {
+	Path string
+	// PkgName is the package name derived from Path.
+	PkgName string
+	AST     *ast.File
+	Package *registry.PackageInfo
+	// Versions are versions of the package in pkg.yaml and GitHub Releases.
+	// They are used to check version_overrides.
+	Versions []string
+	// HasReleases is true if Versions include GitHub Releases of the package.
+	HasReleases bool
+}
+
+// Diagnostic is a violation of a rule with the position.
+type Diagnostic struct {
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Message  string   `json:"message"`
+	Fixable  bool     `json:"fixable"`
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", d.File, d.Line, d.Column, d.Severity, d.Message, d.RuleID)
+}
+
+// Load reads and parses pkgs/<package name>/registry.yaml and versions of the package in pkg.yaml.
+// The file must have only one package.
+func Load(path string) (*File, error) {
+	f, err := LoadRegistry(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.ReadPkgYAML(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// LoadRegistry reads and parses pkgs/<package name>/registry.yaml without pkg.yaml.
+// The file must have only one package.
+func LoadRegistry(path string) (*File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	file, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s as YAML: %w", path, err)
+	}
+	cfg := &registry.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s as YAML: %w", path, err)
+	}
+	switch {
+	case len(cfg.PackageInfos) == 0:
+		return nil, fmt.Errorf("%s: packages is empty", path)
+	case len(cfg.PackageInfos) > 1:
+		return nil, fmt.Errorf("%s: packages must include only one package", path)
+	}
+	return &File{
+		Path:    filepath.ToSlash(path),
+		PkgName: strings.TrimPrefix(filepath.ToSlash(filepath.Dir(path)), "pkgs/"),
+		AST:     file,
+		Package: cfg.PackageInfos[0],
+	}, nil
+}
+
+// ReadPkgYAML reads versions of the package and its aliases in pkg.yaml next to registry.yaml.
+func (f *File) ReadPkgYAML() error {
+	names := []string{f.PkgName}
+	for _, alias := range f.Package.Aliases {
+		names = append(names, alias.Name)
+	}
+	versions, err := readPkgYAMLVersions(filepath.Join(filepath.Dir(f.Path), "pkg.yaml"), names)
+	if err != nil {
+		return err
+	}
+	f.Versions = versions
+	return nil
+}
+
+// Lint checks the file with rules and returns diagnostics in the order of rules.
+func Lint(f *File, rules []Rule) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	for _, rule := range rules {
+		for _, v := range rule.Check(f) {
+			line, column := position(f.AST, v.YAMLPath)
+			diagnostics = append(diagnostics, &Diagnostic{
+				RuleID:   rule.ID(),
+				Severity: rule.Severity(),
+				File:     f.Path,
+				Line:     line,
+				Column:   column,
+				Message:  v.Message,
+				Fixable:  v.Fix != nil,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// maxFixPasses is the maximum number of passes of Fix.
+const maxFixPasses = 10
+
+// Fix fixes violations of rules in the AST of the file and returns the number of fixed violations.
+// A fix may cause violations of other rules, so rules are applied repeatedly until no violation is fixed.
+// The file isn't written.
+func Fix(f *File, rules []Rule) (int, error) {
+	fixed := 0
+	for range maxFixPasses {
+		n, err := fixOnce(f, rules)
+		fixed += n
+		if err != nil || n == 0 {
+			return fixed, err
+		}
+		if err := f.reparse(); err != nil {
+			return fixed, err
+		}
+	}
+	return fixed, nil
+}
+
+func fixOnce(f *File, rules []Rule) (int, error) {
+	if len(f.AST.Docs) == 0 {
+		return 0, errors.New("the file has no document")
+	}
+	body := f.AST.Docs[0].Body
+	fixed := 0
+	for _, rule := range rules {
+		for _, v := range rule.Check(f) {
+			if v.Fix == nil {
+				continue
+			}
+			if err := v.Fix.Run(body); err != nil {
+				return fixed, fmt.Errorf("fix a violation of %s at %s: %w", rule.ID(), v.YAMLPath, err)
+			}
+			fixed++
+		}
+	}
+	return fixed, nil
+}
+
+// reparse parses the fixed AST again so that rules check the fixed package.
+func (f *File) reparse() error {
+	b := []byte(f.AST.String())
+	file, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parse the fixed %s as YAML: %w", f.Path, err)
+	}
+	cfg := &registry.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return fmt.Errorf("parse the fixed %s as YAML: %w", f.Path, err)
+	}
+	if len(cfg.PackageInfos) != 1 {
+		return fmt.Errorf("the fixed %s must include only one package", f.Path)
+	}
+	f.AST = file
+	f.Package = cfg.PackageInfos[0]
+	return nil
+}
+
+// position returns the line and column of the node at the YAML path.
+// It returns 1:1 if the node isn't found.
+func position(file *ast.File, yamlPath string) (int, int) {
+	p, err := yaml.PathString(yamlPath)
+	if err != nil {
+		return 1, 1
+	}
+	node, err := p.FilterFile(file)
+	if err != nil || node == nil {
+		return 1, 1
+	}
+	tk := node.GetToken()
+	if tk == nil || tk.Position == nil {
+		return 1, 1
+	}
+	return tk.Position.Line, tk.Position.Column
+}
@@ -0,0 +1,184 @@
+package lint_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/lint"
+	"github.com/google/go-cmp/cmp"
+)
+
+const registryYAML = `packages:
+  - type: github_release
+    name: cli/cli
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: gh.exe
+        src: gh.exe
+      - name: gh-bin
+    overrides:
+      - goos: windows
+        files:
+          - name: gh
+            src: gh
+`
+
+func writePkg(t *testing.T, pkgName, content string) string {
+	t.Helper()
+	p := filepath.Join("pkgs", filepath.FromSlash(pkgName), "registry.yaml")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	return filepath.ToSlash(p)
+}
+
+func TestLint(t *testing.T) {
+	t.Chdir(t.TempDir())
+	p := writePkg(t, "cli/cli", registryYAML)
+	f, err := lint.Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*lint.Diagnostic{
+		{
+			RuleID:   "exe-suffix",
+			Severity: lint.SeverityWarning,
+			File:     "pkgs/cli/cli/registry.yaml",
+			Line:     7,
+			Column:   15,
+			Message:  `remove .exe from name "gh.exe", remove .exe from src "gh.exe", remove src because it's same as name`,
+			Fixable:  true,
+		},
+		{
+			RuleID:   "exe-suffix",
+			Severity: lint.SeverityWarning,
+			File:     "pkgs/cli/cli/registry.yaml",
+			Line:     13,
+			Column:   19,
+			Message:  "remove src because it's same as name",
+			Fixable:  true,
+		},
+		{
+			RuleID:   "redundant-name",
+			Severity: lint.SeverityWarning,
+			File:     "pkgs/cli/cli/registry.yaml",
+			Line:     3,
+			Column:   11,
+			Message:  `remove name "cli/cli" because it's same as <repo_owner>/<repo_name>`,
+			Fixable:  true,
+		},
+	}
+	if diff := cmp.Diff(exp, lint.Lint(f, lint.Rules())); diff != "" {
+		t.Fatalf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_Fix(t *testing.T) {
+	t.Chdir(t.TempDir())
+	p := writePkg(t, "cli/cli", registryYAML)
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Format: lint.FormatText,
+		Fix:    true,
+		Stdout: buf,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("all violations should be fixed: %s", buf.String())
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: gh
+      - name: gh-bin
+    overrides:
+      - goos: windows
+        files:
+          - name: gh
+`
+	if diff := cmp.Diff(exp, string(b)); diff != "" {
+		t.Fatalf("fixed file (-want +got):\n%s", diff)
+	}
+}
+
+func TestFix_cascade(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// Removing .exe makes files same as the default, so redundant-files must be fixed in the next pass.
+	p := writePkg(t, "cli/cli", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: cli.exe
+`)
+	f, err := lint.Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := lint.Fix(f, lint.Rules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("2 violations should be fixed, got %d", n)
+	}
+	exp := `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`
+	if diff := cmp.Diff(exp, f.AST.String()); diff != "" {
+		t.Fatalf("fixed file (-want +got):\n%s", diff)
+	}
+	if diagnostics := lint.Lint(f, lint.Rules()); len(diagnostics) != 0 {
+		t.Fatalf("no violation should be left: %v", diagnostics)
+	}
+}
+
+func TestRun_SARIF(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writePkg(t, "cli/cli", registryYAML)
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Args:   []string{"cli/cli"},
+		Format: lint.FormatSARIF,
+		Stdout: buf,
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	for _, s := range []string{`"version": "2.1.0"`, `"ruleId": "redundant-name"`, `"uri": "pkgs/cli/cli/registry.yaml"`, `"startLine": 3`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("SARIF should include %s: %s", s, buf.String())
+		}
+	}
+}
+
+func TestRun_url(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writePkg(t, "cli/cli", registryYAML)
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Args:   []string{"https://github.com/cli/cli"},
+		Format: lint.FormatText,
+		Stdout: buf,
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	if !strings.Contains(buf.String(), "pkgs/cli/cli/registry.yaml") {
+		t.Fatalf("the package should be linted: %s", buf.String())
+	}
+}
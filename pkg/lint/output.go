@@ -0,0 +1,148 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteText outputs diagnostics in the format file:line:col: severity: message (rule).
+func WriteText(w io.Writer, diagnostics []*Diagnostic) error {
+	for _, d := range diagnostics {
+		if _, err := fmt.Fprintln(w, d.String()); err != nil {
+			return fmt.Errorf("write a diagnostic: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteJSON outputs diagnostics as a JSON array.
+func WriteJSON(w io.Writer, diagnostics []*Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []*Diagnostic{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(diagnostics); err != nil {
+		return fmt.Errorf("encode diagnostics as JSON: %w", err)
+	}
+	return nil
+}
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId"`
+	Level     string           `json:"level"`
+	Message   *sarifMessage    `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// sarifLevel converts the severity to the level of SARIF.
+func sarifLevel(s Severity) string {
+	if s == SeverityInfo {
+		return "note"
+	}
+	return string(s)
+}
+
+// WriteSARIF outputs diagnostics in SARIF 2.1.0 so that they can be uploaded to GitHub Code Scanning.
+func WriteSARIF(w io.Writer, rules []Rule, diagnostics []*Diagnostic) error {
+	driver := &sarifDriver{
+		Name:           "argd",
+		InformationURI: "https://github.com/aquaproj/registry-tool",
+		Rules:          make([]*sarifRule, len(rules)),
+	}
+	for i, rule := range rules {
+		driver.Rules[i] = &sarifRule{
+			ID:                   rule.ID(),
+			ShortDescription:     &sarifMessage{Text: rule.Doc()},
+			DefaultConfiguration: &sarifConfiguration{Level: sarifLevel(rule.Severity())},
+		}
+	}
+	run := &sarifRun{
+		Tool:    &sarifTool{Driver: driver},
+		Results: make([]*sarifResult, len(diagnostics)),
+	}
+	for i, d := range diagnostics {
+		run.Results[i] = &sarifResult{
+			RuleID:  d.RuleID,
+			Level:   sarifLevel(d.Severity),
+			Message: &sarifMessage{Text: d.Message},
+			Locations: []*sarifLocation{
+				{
+					PhysicalLocation: &sarifPhysicalLocation{
+						ArtifactLocation: &sarifArtifactLocation{URI: d.File},
+						Region: &sarifRegion{
+							StartLine:   d.Line,
+							StartColumn: d.Column,
+						},
+					},
+				},
+			},
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(&sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []*sarifRun{run},
+	}); err != nil {
+		return fmt.Errorf("encode diagnostics as SARIF: %w", err)
+	}
+	return nil
+}
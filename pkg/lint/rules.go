@@ -0,0 +1,151 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/suzuki-shunsuke/go-yamledit/yamledit"
+)
+
+// Rules returns all rules.
+func Rules() []Rule {
+	return []Rule{
+		&exeSuffixRule{},
+		&redundantNameRule{},
+		&redundantOverrideRule{},
+		&identityReplacementRule{},
+		&redundantSupportedEnvsRule{},
+		&redundantFilesRule{},
+		&unreachableVersionOverrideRule{},
+		&uncoveredVersionsRule{},
+		&mergeableVersionOverridesRule{},
+	}
+}
+
+// exeSuffixRule removes the suffix .exe from files[].name and files[].src.
+// aqua appends .exe on Windows, so the suffix is unnecessary.
+type exeSuffixRule struct{}
+
+func (r *exeSuffixRule) ID() string {
+	return "exe-suffix"
+}
+
+func (r *exeSuffixRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *exeSuffixRule) Doc() string {
+	return `files[].name and files[].src must not have the suffix .exe because aqua appends .exe on Windows.
+files[].src must be omitted if it's same as files[].name.`
+}
+
+func (r *exeSuffixRule) Check(f *File) []*Violation {
+	var violations []*Violation
+	for path, file := range packageFiles(f.Package) {
+		if v := r.checkFile(path, file); v != nil {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+func (r *exeSuffixRule) checkFile(path string, file *registry.File) *Violation {
+	name, nameHasExe := strings.CutSuffix(file.Name, ".exe")
+	src, srcHasExe := strings.CutSuffix(file.Src, ".exe")
+	var actions []yamledit.MappingNodeAction
+	var messages []string
+	nodePath := path + ".name"
+	if nameHasExe {
+		actions = append(actions, yamledit.SetKey("name", name, nil))
+		messages = append(messages, fmt.Sprintf("remove .exe from name %q", file.Name))
+	}
+	if srcHasExe {
+		actions = append(actions, yamledit.SetKey("src", src, nil))
+		messages = append(messages, fmt.Sprintf("remove .exe from src %q", file.Src))
+		if !nameHasExe {
+			nodePath = path + ".src"
+		}
+	}
+	if file.Src != "" && name == src {
+		actions = append(actions, yamledit.RemoveKeys("src"))
+		messages = append(messages, "remove src because it's same as name")
+	}
+	if len(actions) == 0 {
+		return nil
+	}
+	return &Violation{
+		YAMLPath: nodePath,
+		Message:  strings.Join(messages, ", "),
+		Fix:      yamledit.MapAction(path, actions...),
+	}
+}
+
+// packageFiles returns files of the package with YAML paths.
+// .files
+// .overrides[].files
+// .version_overrides[].files
+// .version_overrides[].overrides[].files
+func packageFiles(pkg *registry.PackageInfo) func(yield func(string, *registry.File) bool) {
+	return func(yield func(string, *registry.File) bool) {
+		for i, file := range pkg.Files {
+			if !yield(fmt.Sprintf("$.packages[0].files[%d]", i), file) {
+				return
+			}
+		}
+		for i, ov := range pkg.Overrides {
+			for j, file := range ov.Files {
+				if !yield(fmt.Sprintf("$.packages[0].overrides[%d].files[%d]", i, j), file) {
+					return
+				}
+			}
+		}
+		for i, vov := range pkg.VersionOverrides {
+			for j, file := range vov.Files {
+				if !yield(fmt.Sprintf("$.packages[0].version_overrides[%d].files[%d]", i, j), file) {
+					return
+				}
+			}
+			for j, ov := range vov.Overrides {
+				for k, file := range ov.Files {
+					if !yield(fmt.Sprintf("$.packages[0].version_overrides[%d].overrides[%d].files[%d]", i, j, k), file) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// redundantNameRule removes name if it's same as <repo_owner>/<repo_name>.
+type redundantNameRule struct{}
+
+func (r *redundantNameRule) ID() string {
+	return "redundant-name"
+}
+
+func (r *redundantNameRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *redundantNameRule) Doc() string {
+	return `name must be omitted if it's same as <repo_owner>/<repo_name>.
+Packages whose names include periods are ignored.`
+}
+
+func (r *redundantNameRule) Check(f *File) []*Violation {
+	pkg := f.Package
+	if strings.Contains(f.PkgName, ".") || pkg.RepoOwner == "" || pkg.RepoName == "" {
+		return nil
+	}
+	if pkg.Name != pkg.RepoOwner+"/"+pkg.RepoName {
+		return nil
+	}
+	return []*Violation{
+		{
+			YAMLPath: "$.packages[0].name",
+			Message:  fmt.Sprintf("remove name %q because it's same as <repo_owner>/<repo_name>", pkg.Name),
+			Fix:      yamledit.MapAction("$.packages[0]", yamledit.RemoveKeys("name")),
+		},
+	}
+}
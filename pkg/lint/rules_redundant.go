@@ -0,0 +1,265 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/suzuki-shunsuke/go-yamledit/yamledit"
+)
+
+// redundantOverrideRule removes format and asset of overrides and version_overrides if they are same as the parent's.
+type redundantOverrideRule struct{}
+
+func (r *redundantOverrideRule) ID() string {
+	return "redundant-override"
+}
+
+func (r *redundantOverrideRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *redundantOverrideRule) Doc() string {
+	return `format and asset of overrides, version_overrides, and version_overrides[].overrides must be omitted if they are same as the parent's.
+format isn't checked if format_overrides of the parent may change the format.
+overrides of the package are also compared with version_overrides without overrides because they apply to them too.`
+}
+
+func (r *redundantOverrideRule) Check(f *File) []*Violation {
+	var violations []*Violation
+	for _, s := range scopes(f.Package) {
+		if s.Parent == nil || s.changesPackage() {
+			continue
+		}
+		if s.Format != "" && r.sameFormat(s) {
+			violations = append(violations, r.violation(s, "format", s.Format))
+		}
+		if s.Asset != "" && r.sameAsset(s) {
+			violations = append(violations, r.violation(s, "asset", s.Asset))
+		}
+	}
+	return violations
+}
+
+// sameFormat returns true if the format is same as all parents' and format_overrides of them don't change the format.
+func (r *redundantOverrideRule) sameFormat(s *scope) bool {
+	for _, p := range s.parents() {
+		if s.Format != p.Format || r.formatOverridden(s, p) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *redundantOverrideRule) sameAsset(s *scope) bool {
+	for _, p := range s.parents() {
+		if s.Asset != p.Asset {
+			return false
+		}
+	}
+	return true
+}
+
+// formatOverridden returns true if format_overrides of the parent may change the format for the override.
+func (r *redundantOverrideRule) formatOverridden(s, parent *scope) bool {
+	if s.Kind != scopeOverride {
+		return false
+	}
+	for _, fo := range parent.FormatOverrides {
+		if s.GOOS == "" || fo.GOOS == s.GOOS {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redundantOverrideRule) violation(s *scope, key, value string) *Violation {
+	return &Violation{
+		YAMLPath: s.YAMLPath + "." + key,
+		Message:  fmt.Sprintf("remove %s %q because it's same as the parent's", key, value),
+		Fix:      yamledit.MapAction(s.YAMLPath, yamledit.RemoveKeys(key)),
+	}
+}
+
+// identityReplacementRule removes replacements mapping an OS or an arch to itself.
+type identityReplacementRule struct{}
+
+func (r *identityReplacementRule) ID() string {
+	return "identity-replacement"
+}
+
+func (r *identityReplacementRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *identityReplacementRule) Doc() string {
+	return `replacements must not map an OS or an arch to itself.
+Replacements of overrides are merged with the parent's, so they are kept if they cancel the parent's replacements.`
+}
+
+func (r *identityReplacementRule) Check(f *File) []*Violation {
+	var violations []*Violation
+	for _, s := range scopes(f.Package) {
+		if v := r.check(s); v != nil {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+func (r *identityReplacementRule) check(s *scope) *Violation {
+	var keys []string
+	for k, v := range s.Replacements {
+		if k != v {
+			continue
+		}
+		// Replacements of overrides are merged with the parent's, so the entry may cancel the parent's.
+		if s.Kind == scopeOverride && r.cancelsParent(s, k) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	slices.Sort(keys)
+	v := &Violation{
+		YAMLPath: s.YAMLPath + ".replacements." + keys[0],
+		Message:  fmt.Sprintf("remove replacements mapping to themselves: %s", strings.Join(keys, ", ")),
+	}
+	if len(keys) < len(s.Replacements) {
+		anyKeys := make([]any, len(keys))
+		for i, k := range keys {
+			anyKeys[i] = k
+		}
+		v.Fix = yamledit.MapAction(s.YAMLPath+".replacements", yamledit.RemoveKeys(anyKeys...))
+		return v
+	}
+	// All entries are removed.
+	// Replacements of version_overrides replace the package's, so removing them changes the result
+	// if the package has replacements.
+	if s.Kind == scopeVersionOverride && hasReplacement(s.Parent.Replacements) {
+		return nil
+	}
+	v.Fix = yamledit.MapAction(s.YAMLPath, yamledit.RemoveKeys("replacements"))
+	return v
+}
+
+// cancelsParent returns true if the replacement of the key cancels any parent's replacement.
+func (r *identityReplacementRule) cancelsParent(s *scope, k string) bool {
+	for _, p := range s.parents() {
+		if pv, ok := p.Replacements[k]; ok && pv != k {
+			return true
+		}
+	}
+	return false
+}
+
+func hasReplacement(replacements registry.Replacements) bool {
+	for k, v := range replacements {
+		if k != v {
+			return true
+		}
+	}
+	return false
+}
+
+// redundantSupportedEnvsRule removes supported_envs listing every platform.
+type redundantSupportedEnvsRule struct{}
+
+func (r *redundantSupportedEnvsRule) ID() string {
+	return "redundant-supported-envs"
+}
+
+func (r *redundantSupportedEnvsRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *redundantSupportedEnvsRule) Doc() string {
+	return `supported_envs must be omitted if it lists every platform.
+supported_envs of version_overrides is kept if the package's supported_envs doesn't list every platform.`
+}
+
+func (r *redundantSupportedEnvsRule) Check(f *File) []*Violation {
+	var violations []*Violation
+	for _, s := range scopes(f.Package) {
+		if s.Kind == scopeOverride || len(s.SupportedEnvs) == 0 || !supportsAllPlatforms(s.SupportedEnvs) {
+			continue
+		}
+		if s.Parent != nil && !supportsAllPlatforms(s.Parent.SupportedEnvs) {
+			continue
+		}
+		violations = append(violations, &Violation{
+			YAMLPath: s.YAMLPath + ".supported_envs",
+			Message:  "remove supported_envs because it lists every platform",
+			Fix:      yamledit.MapAction(s.YAMLPath, yamledit.RemoveKeys("supported_envs")),
+		})
+	}
+	return violations
+}
+
+// redundantFilesRule removes files which is same as the default derived from the package name.
+type redundantFilesRule struct{}
+
+func (r *redundantFilesRule) ID() string {
+	return "redundant-files"
+}
+
+func (r *redundantFilesRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *redundantFilesRule) Doc() string {
+	return `files must be omitted if it has only a file whose name is derived from the package name and other fields are empty.
+files of overrides and version_overrides is kept if the parent's files isn't the default.
+overrides of the package are also compared with version_overrides without overrides because they apply to them too.`
+}
+
+func (r *redundantFilesRule) Check(f *File) []*Violation {
+	defaultFiles := []*registry.File{{Name: defaultCmdName(f.Package)}}
+	var violations []*Violation
+	for _, s := range scopes(f.Package) {
+		if len(s.Files) == 0 || !sameFiles(s.Files, defaultFiles) {
+			continue
+		}
+		if s.Parent != nil && (s.changesPackage() || !r.defaultParents(s, defaultFiles)) {
+			continue
+		}
+		violations = append(violations, &Violation{
+			YAMLPath: s.YAMLPath + ".files",
+			Message:  fmt.Sprintf("remove files because it's same as the default %q", defaultFiles[0].Name),
+			Fix:      yamledit.MapAction(s.YAMLPath, yamledit.RemoveKeys("files")),
+		})
+	}
+	return violations
+}
+
+// defaultParents returns true if files of all parents are the default.
+// Version overrides changing the package may change the default, so they aren't the default.
+func (r *redundantFilesRule) defaultParents(s *scope, defaultFiles []*registry.File) bool {
+	for _, p := range s.parents() {
+		if len(p.Files) != 0 && !sameFiles(p.Files, defaultFiles) {
+			return false
+		}
+	}
+	for _, p := range s.Inherited {
+		if p.changesPackage() {
+			return false
+		}
+	}
+	return true
+}
+
+func sameFiles(a, b []*registry.File) bool {
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ab) == string(bb)
+}
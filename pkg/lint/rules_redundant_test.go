@@ -0,0 +1,144 @@
+package lint_test
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/lint"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRun_Redundant(t *testing.T) {
+	t.Chdir(t.TempDir())
+	p := writePkg(t, "suzuki-shunsuke/tfcmt", `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.{{.Format}}
+    format: tar.gz
+    # The default file
+    files:
+      - name: tfcmt
+    replacements:
+      amd64: x86_64
+      arm64: arm64 # identity
+    supported_envs:
+      - darwin
+      - linux
+      - windows
+    overrides:
+      - goos: windows
+        format: zip
+      - goos: linux
+        # same as the parent
+        format: tar.gz
+        replacements:
+          amd64: amd64 # cancel the parent's replacement
+    version_overrides:
+      - version_constraint: semver("< 1.0.0")
+        asset: tfcmt_{{.OS}}_{{.Arch}}.{{.Format}}
+        replacements:
+          darwin: darwin
+        supported_envs:
+          - all
+        overrides:
+          - goos: darwin
+            format: tar.gz
+      - version_constraint: "true"
+        files:
+          - name: tfcmt
+`)
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Format: lint.FormatText,
+		Stdout: buf,
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	exp := `pkgs/suzuki-shunsuke/tfcmt/registry.yaml:22:17: warning: remove format "tar.gz" because it's same as the parent's (redundant-override)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:27:16: warning: remove asset "tfcmt_{{.OS}}_{{.Arch}}.{{.Format}}" because it's same as the parent's (redundant-override)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:34:21: warning: remove format "tar.gz" because it's same as the parent's (redundant-override)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:12:14: warning: remove replacements mapping to themselves: arm64 (identity-replacement)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:14:7: warning: remove supported_envs because it lists every platform (redundant-supported-envs)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:31:11: warning: remove supported_envs because it lists every platform (redundant-supported-envs)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:9:7: warning: remove files because it's same as the default "tfcmt" (redundant-files)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:37:11: warning: remove files because it's same as the default "tfcmt" (redundant-files)
+`
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("diagnostics (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Format: lint.FormatText,
+		Fix:    true,
+		Stdout: buf,
+	}); err != nil {
+		t.Fatalf("all violations should be fixed: %v\n%s", err, buf.String())
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expFixed := `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.{{.Format}}
+    format: tar.gz
+    replacements:
+      amd64: x86_64
+    overrides:
+      - goos: windows
+        format: zip
+      - goos: linux
+        replacements:
+          amd64: amd64 # cancel the parent's replacement
+    version_overrides:
+      - version_constraint: semver("< 1.0.0")
+        replacements:
+          darwin: darwin
+        overrides:
+          - goos: darwin
+      - version_constraint: "true"
+`
+	if diff := cmp.Diff(expFixed, string(b)); diff != "" {
+		t.Fatalf("fixed file (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_RedundantInheritedOverrides(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// overrides of the package also apply to version_overrides without overrides
+	content := `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.{{.Format}}
+    format: tar.gz
+    overrides:
+      - goos: darwin
+        format: tar.gz
+    version_overrides:
+      - version_constraint: semver("< 1.0.0")
+        format: zip
+`
+	p := writePkg(t, "suzuki-shunsuke/tfcmt", content)
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Format: lint.FormatText,
+		Fix:    true,
+		Stdout: buf,
+	}); err != nil {
+		t.Fatalf("no violation should be found: %v\n%s", err, buf.String())
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(content, string(b)); diff != "" {
+		t.Fatalf("the file must not be changed (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,111 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/aquaproj/registry-tool/pkg/pkgfile"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+var errViolations = errors.New("violations are found")
+
+// Config is the configuration of Run.
+type Config struct {
+	// Args are package names or paths to pkgs/**/registry.yaml.
+	// If it's empty, all pkgs/**/registry.yaml are linted.
+	Args []string
+	// Format is the output format. text, json, or sarif.
+	Format string
+	// Fix fixes violations of rules that can fix themselves and writes files.
+	// Only violations which remain are output.
+	Fix bool
+	// Stdout is the writer diagnostics are output to.
+	Stdout io.Writer
+	// Releases lists GitHub Releases. If it isn't nil, version_overrides are checked with GitHub Releases too.
+	Releases ReleaseLister
+}
+
+// Run lints files with all rules and outputs diagnostics.
+// It returns an error if any error or warning is found.
+// Info diagnostics are output but don't make Run fail.
+func Run(ctx context.Context, logger *slog.Logger, cfg *Config) error {
+	switch cfg.Format {
+	case FormatText, FormatJSON, FormatSARIF:
+	default:
+		return fmt.Errorf("format must be text, json, or sarif: %s", cfg.Format)
+	}
+	paths, err := pkgfile.Targets(ctx, logger, cfg.Args)
+	if err != nil {
+		return err
+	}
+	rules := Rules()
+	var diagnostics []*Diagnostic
+	for _, p := range paths {
+		f, err := Load(p)
+		if err != nil {
+			return err
+		}
+		if cfg.Fix {
+			if err := fixFile(logger, f, rules); err != nil {
+				return err
+			}
+		}
+		if cfg.Releases != nil {
+			if err := addReleaseVersions(ctx, cfg.Releases, f); err != nil {
+				return err
+			}
+		}
+		diagnostics = append(diagnostics, Lint(f, rules)...)
+	}
+	if err := write(cfg.Stdout, cfg.Format, rules, diagnostics); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(diagnostics, func(d *Diagnostic) bool {
+		return d.Severity != SeverityInfo
+	}) {
+		return errViolations
+	}
+	return nil
+}
+
+func fixFile(logger *slog.Logger, f *File, rules []Rule) error {
+	fixed, err := Fix(f, rules)
+	if err != nil {
+		return err
+	}
+	if fixed == 0 {
+		return nil
+	}
+	if err := pkgfile.Write(f.Path, []byte(f.AST.String())); err != nil {
+		return err
+	}
+	logger.Info("fixed violations", "file", f.Path, "count", fixed)
+	// Reload the file to report violations which remain with the positions in the fixed file.
+	reloaded, err := Load(f.Path)
+	if err != nil {
+		return err
+	}
+	*f = *reloaded
+	return nil
+}
+
+func write(w io.Writer, format string, rules []Rule, diagnostics []*Diagnostic) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, diagnostics)
+	case FormatSARIF:
+		return WriteSARIF(w, rules, diagnostics)
+	default:
+		return WriteText(w, diagnostics)
+	}
+}
@@ -0,0 +1,191 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+)
+
+type scopeKind int
+
+const (
+	scopePackage scopeKind = iota
+	scopeVersionOverride
+	scopeOverride
+)
+
+// scope is a package, a version override, or an override in registry.yaml.
+// Fields are settings written in the scope.
+type scope struct {
+	// YAMLPath is the path to the scope. e.g. $.packages[0].version_overrides[0]
+	YAMLPath string
+	Kind     scopeKind
+	// Type, RepoOwner, RepoName, and Path change the package, so settings can't be compared with the parent.
+	Type            string
+	RepoOwner       string
+	RepoName        string
+	Path            string
+	GOOS            string
+	Format          string
+	Asset           string
+	Files           []*registry.File
+	Replacements    registry.Replacements
+	SupportedEnvs   registry.SupportedEnvs
+	FormatOverrides registry.FormatOverrides
+	// Parent is the effective settings inherited from the parent scope. It's nil if Kind is scopePackage.
+	Parent *scope
+	// Inherited is the effective settings of version_overrides without overrides.
+	// Overrides of the package also apply to them like aqua does, so it's set only for overrides of the package.
+	Inherited []*scope
+}
+
+// changesPackage returns true if the scope changes the type or the repository of the package.
+func (s *scope) changesPackage() bool {
+	return s.Type != "" || s.RepoOwner != "" || s.RepoName != "" || s.Path != ""
+}
+
+// parents returns the effective settings of all scopes the scope applies to.
+func (s *scope) parents() []*scope {
+	if s.Parent == nil {
+		return nil
+	}
+	return append([]*scope{s.Parent}, s.Inherited...)
+}
+
+// scopes returns the package, version_overrides, overrides, and version_overrides[].overrides of the package.
+func scopes(pkg *registry.PackageInfo) []*scope {
+	root := &scope{
+		YAMLPath:        "$.packages[0]",
+		Kind:            scopePackage,
+		Type:            pkg.Type,
+		Format:          pkg.Format,
+		Asset:           pkg.Asset,
+		Files:           pkg.Files,
+		Replacements:    pkg.Replacements,
+		SupportedEnvs:   pkg.SupportedEnvs,
+		FormatOverrides: pkg.FormatOverrides,
+	}
+	rootOverrides := overrideScopes(root, pkg.Overrides)
+	ret := append([]*scope{root}, rootOverrides...)
+	var inherited []*scope
+	for i, vov := range pkg.VersionOverrides {
+		s := &scope{
+			YAMLPath:        fmt.Sprintf("%s.version_overrides[%d]", root.YAMLPath, i),
+			Kind:            scopeVersionOverride,
+			Type:            vov.Type,
+			RepoOwner:       vov.RepoOwner,
+			RepoName:        vov.RepoName,
+			Path:            vov.Path,
+			Format:          vov.Format,
+			Asset:           vov.Asset,
+			Files:           vov.Files,
+			Replacements:    vov.Replacements,
+			SupportedEnvs:   vov.SupportedEnvs,
+			FormatOverrides: vov.FormatOverrides,
+			Parent:          root,
+		}
+		ret = append(ret, s)
+		merged := mergeVersionOverride(root, s)
+		if vov.Overrides == nil {
+			// Overrides of the package apply to the version override.
+			inherited = append(inherited, merged)
+			continue
+		}
+		// Overrides of the version override inherit settings merged like aqua does.
+		ret = append(ret, overrideScopes(merged, vov.Overrides)...)
+	}
+	for _, ov := range rootOverrides {
+		ov.Inherited = inherited
+	}
+	return ret
+}
+
+func overrideScopes(parent *scope, overrides registry.Overrides) []*scope {
+	ret := make([]*scope, len(overrides))
+	for i, ov := range overrides {
+		ret[i] = &scope{
+			YAMLPath:     fmt.Sprintf("%s.overrides[%d]", parent.YAMLPath, i),
+			Kind:         scopeOverride,
+			Type:         ov.Type,
+			Path:         ov.Path,
+			GOOS:         ov.GOOS,
+			Format:       ov.Format,
+			Asset:        ov.Asset,
+			Files:        ov.Files,
+			Replacements: ov.Replacements,
+			Parent:       parent,
+		}
+	}
+	return ret
+}
+
+// mergeVersionOverride returns the effective settings of the version override.
+// Settings of the version override replace those of the package.
+// The type and the repository are those written in the version override so that changesPackage works.
+func mergeVersionOverride(pkg, vov *scope) *scope {
+	s := *pkg
+	s.YAMLPath = vov.YAMLPath
+	s.Kind = vov.Kind
+	s.Type = vov.Type
+	s.RepoOwner = vov.RepoOwner
+	s.RepoName = vov.RepoName
+	s.Path = vov.Path
+	if vov.Format != "" {
+		s.Format = vov.Format
+	}
+	if vov.Asset != "" {
+		s.Asset = vov.Asset
+	}
+	if vov.Files != nil {
+		s.Files = vov.Files
+	}
+	if vov.Replacements != nil {
+		s.Replacements = vov.Replacements
+	}
+	if vov.SupportedEnvs != nil {
+		s.SupportedEnvs = vov.SupportedEnvs
+	}
+	if vov.FormatOverrides != nil {
+		s.FormatOverrides = vov.FormatOverrides
+	}
+	return &s
+}
+
+// defaultCmdName returns the name of the command installed if files isn't set.
+// It's same as aqua.
+func defaultCmdName(pkg *registry.PackageInfo) string {
+	if pkg.HasRepo() {
+		if pkg.Name == "" {
+			return pkg.RepoName
+		}
+		return path.Base(pkg.Name)
+	}
+	if pkg.Type == registry.PkgInfoTypeGoInstall {
+		return path.Base(pkg.GetPath())
+	}
+	return path.Base(pkg.GetName())
+}
+
+var allPlatforms = []string{ //nolint:gochecknoglobals
+	"darwin/amd64", "darwin/arm64",
+	"linux/amd64", "linux/arm64",
+	"windows/amd64", "windows/arm64",
+}
+
+// supportsAllPlatforms returns true if supported_envs is empty or covers all platforms.
+func supportsAllPlatforms(envs registry.SupportedEnvs) bool {
+	if len(envs) == 0 {
+		return true
+	}
+	covered := map[string]struct{}{}
+	for _, env := range envs {
+		for _, platform := range allPlatforms {
+			goos, goarch := path.Split(platform)
+			if env == "all" || env == platform || env+"/" == goos || env == goarch {
+				covered[platform] = struct{}{}
+			}
+		}
+	}
+	return len(covered) == len(allPlatforms)
+}
@@ -0,0 +1,291 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/constraint"
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/semver"
+	"github.com/goccy/go-yaml"
+)
+
+type pkgYAML struct {
+	Packages []struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"packages"`
+}
+
+// readPkgYAMLVersions returns versions of the package in pkg.yaml.
+// names are the package name and its aliases, and entries with any of them are read.
+// It returns nil if pkg.yaml doesn't exist.
+func readPkgYAMLVersions(path string, names []string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	pkgs := &pkgYAML{}
+	if err := yaml.Unmarshal(b, pkgs); err != nil {
+		return nil, fmt.Errorf("parse %s as YAML: %w", path, err)
+	}
+	var versions []string
+	for _, pkg := range pkgs.Packages {
+		name, v, ok := strings.Cut(pkg.Name, "@")
+		if !ok {
+			v = pkg.Version
+		}
+		if v == "" || !slices.Contains(names, name) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// ReleaseLister lists GitHub Releases.
+type ReleaseLister interface {
+	ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+}
+
+// addReleaseVersions adds tags of GitHub Releases of the package to f.Versions.
+// Draft releases are ignored.
+func addReleaseVersions(ctx context.Context, gh ReleaseLister, f *File) error {
+	pkg := f.Package
+	if pkg.RepoOwner == "" || pkg.RepoName == "" {
+		return nil
+	}
+	opts := &github.ListOptions{
+		PerPage: 100, //nolint:mnd
+	}
+	for range 10 {
+		releases, resp, err := gh.ListReleases(ctx, pkg.RepoOwner, pkg.RepoName, opts)
+		if err != nil {
+			return fmt.Errorf("list releases of %s/%s: %w", pkg.RepoOwner, pkg.RepoName, err)
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue
+			}
+			f.Versions = append(f.Versions, release.GetTagName())
+		}
+		if resp == nil || resp.NextPage == 0 {
+			f.HasReleases = true
+			return nil
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil
+}
+
+// sortVersions sorts versions in ascending order and removes duplicates.
+func sortVersions(versions []string) []string {
+	versions = slices.Clone(versions)
+	slices.SortFunc(versions, func(a, b string) int {
+		switch {
+		case semver.GreaterThan(a, b):
+			return 1
+		case semver.GreaterThan(b, a):
+			return -1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+	return slices.Compact(versions)
+}
+
+// versionAnalysis is the result of evaluating version_constraint of the package and version_overrides with versions.
+type versionAnalysis struct {
+	// selected is versions selected by each version_overrides entry in the same way as aqua.
+	selected [][]string
+	// matched is versions matching version_constraint of each version_overrides entry.
+	matched [][]string
+	// selector is a map from versions to the index of the entry selecting them.
+	// constraint.TopLevel means the version_constraint of the package.
+	selector map[string]int
+	// uncovered is ranges of consecutive versions which no entry selects.
+	uncovered [][]string
+}
+
+// analyzeVersions evaluates constraints with versions.
+// It returns nil if the package has no version_constraint or no version is found.
+func analyzeVersions(pkg *registry.PackageInfo, versions []string) *versionAnalysis {
+	if pkg.VersionConstraints == "" || len(versions) == 0 {
+		return nil
+	}
+	a := &versionAnalysis{
+		selected: make([][]string, len(pkg.VersionOverrides)),
+		matched:  make([][]string, len(pkg.VersionOverrides)),
+		selector: map[string]int{},
+	}
+	var gap []string
+	for _, v := range sortVersions(versions) {
+		selector, ok := constraint.TopLevel, constraint.Match(pkg.VersionConstraints, pkg.VersionPrefix, v)
+		for i, vo := range pkg.VersionOverrides {
+			if !constraint.Match(vo.VersionConstraints, constraint.Prefix(pkg, i), v) {
+				continue
+			}
+			a.matched[i] = append(a.matched[i], v)
+			if !ok {
+				selector, ok = i, true
+				a.selected[i] = append(a.selected[i], v)
+			}
+		}
+		if !ok {
+			gap = append(gap, v)
+			continue
+		}
+		a.selector[v] = selector
+		if len(gap) > 0 {
+			a.uncovered = append(a.uncovered, gap)
+			gap = nil
+		}
+	}
+	if len(gap) > 0 {
+		a.uncovered = append(a.uncovered, gap)
+	}
+	return a
+}
+
+func entryName(idx int) string {
+	if idx == constraint.TopLevel {
+		return "version_constraint of the package"
+	}
+	return fmt.Sprintf("version_overrides[%d]", idx)
+}
+
+// unreachableVersionOverrideRule reports version_overrides entries which no version selects.
+type unreachableVersionOverrideRule struct{}
+
+func (r *unreachableVersionOverrideRule) ID() string {
+	return "unreachable-version-override"
+}
+
+func (r *unreachableVersionOverrideRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *unreachableVersionOverrideRule) Doc() string {
+	return `version_overrides entries must be selected by some versions in pkg.yaml (and GitHub Releases with --releases).
+An entry is unreachable if the version_constraint of the package or earlier entries cover all versions matching it.
+Entries matching no version are reported only with --releases because pkg.yaml doesn't include all versions.`
+}
+
+func (r *unreachableVersionOverrideRule) Check(f *File) []*Violation {
+	a := analyzeVersions(f.Package, f.Versions)
+	if a == nil {
+		return nil
+	}
+	var violations []*Violation
+	for i, selected := range a.selected {
+		if len(selected) > 0 {
+			continue
+		}
+		var msg string
+		switch matched := a.matched[i]; {
+		case len(matched) > 0:
+			msg = fmt.Sprintf("version_overrides[%d] is unreachable because %s covers all versions matching it (e.g. %s)",
+				i, entryName(a.selector[matched[0]]), matched[0])
+		case f.HasReleases:
+			msg = fmt.Sprintf("version_overrides[%d] matches no version", i)
+		default:
+			// pkg.yaml doesn't include all versions, so the entry may match versions not in pkg.yaml.
+			continue
+		}
+		violations = append(violations, &Violation{
+			YAMLPath: fmt.Sprintf("$.packages[0].version_overrides[%d].version_constraint", i),
+			Message:  msg,
+		})
+	}
+	return violations
+}
+
+// uncoveredVersionsRule reports versions which no version_constraint matches.
+type uncoveredVersionsRule struct{}
+
+func (r *uncoveredVersionsRule) ID() string {
+	return "uncovered-versions"
+}
+
+func (r *uncoveredVersionsRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *uncoveredVersionsRule) Doc() string {
+	return `Every version in pkg.yaml (and GitHub Releases with --releases) must match the version_constraint of the package or any version_overrides entry.`
+}
+
+func (r *uncoveredVersionsRule) Check(f *File) []*Violation {
+	a := analyzeVersions(f.Package, f.Versions)
+	if a == nil {
+		return nil
+	}
+	violations := make([]*Violation, len(a.uncovered))
+	for i, versions := range a.uncovered {
+		rng := versions[0]
+		if len(versions) > 1 {
+			rng = fmt.Sprintf("%s - %s (%d versions)", versions[0], versions[len(versions)-1], len(versions))
+		}
+		violations[i] = &Violation{
+			YAMLPath: "$.packages[0].version_constraint",
+			Message:  "no version_constraint matches " + rng,
+		}
+	}
+	return violations
+}
+
+// mergeableVersionOverridesRule reports adjacent version_overrides entries with identical bodies.
+type mergeableVersionOverridesRule struct{}
+
+func (r *mergeableVersionOverridesRule) ID() string {
+	return "mergeable-version-overrides"
+}
+
+func (r *mergeableVersionOverridesRule) Severity() Severity {
+	return SeverityInfo
+}
+
+func (r *mergeableVersionOverridesRule) Doc() string {
+	return `Adjacent version_overrides entries could be merged if they are same except for version_constraint.`
+}
+
+func (r *mergeableVersionOverridesRule) Check(f *File) []*Violation {
+	vos := f.Package.VersionOverrides
+	var violations []*Violation
+	for i := 1; i < len(vos); i++ {
+		if !sameVersionOverrideBody(vos[i-1], vos[i]) {
+			continue
+		}
+		violations = append(violations, &Violation{
+			YAMLPath: fmt.Sprintf("$.packages[0].version_overrides[%d].version_constraint", i),
+			Message:  fmt.Sprintf("version_overrides[%d] could be merged with version_overrides[%d] because they are same except for version_constraint", i, i-1),
+		})
+	}
+	return violations
+}
+
+func sameVersionOverrideBody(a, b *registry.VersionOverride) bool {
+	ac := *a
+	bc := *b
+	ac.VersionConstraints = ""
+	bc.VersionConstraints = ""
+	ab, err := json.Marshal(&ac)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(&bc)
+	if err != nil {
+		return false
+	}
+	return string(ab) == string(bb)
+}
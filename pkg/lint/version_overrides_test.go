@@ -0,0 +1,160 @@
+package lint_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/lint"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeReleaseLister struct {
+	tags []string
+}
+
+func (f *fakeReleaseLister) ListReleases(_ context.Context, _, _ string, _ *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	releases := make([]*github.RepositoryRelease, len(f.tags))
+	for i, tag := range f.tags {
+		releases[i] = &github.RepositoryRelease{
+			TagName: tag,
+		}
+	}
+	releases = append(releases, &github.RepositoryRelease{
+		TagName: "v9.9.9",
+		Draft:   true,
+	})
+	return releases, &github.Response{}, nil
+}
+
+func TestRun_versionOverrides(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writePkg(t, "suzuki-shunsuke/tfcmt", `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.tar.gz
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: semver("< 1.0.0")
+        asset: tfcmt_{{.OS}}.tar.gz
+      - version_constraint: semver("< 0.5.0")
+        asset: tfcmt.tar.gz
+      - version_constraint: semver(">= 2.0.0") && semver("< 3.0.0")
+        asset: tfcmt_{{.Arch}}.tar.gz
+      - version_constraint: semver(">= 3.0.0") && semver("< 4.0.0")
+        asset: tfcmt_{{.Arch}}.tar.gz
+      - version_constraint: semver(">= 10.0.0")
+        asset: tfcmt_{{.OS}}_{{.Arch}}.zip
+`)
+	if err := os.WriteFile(filepath.Join("pkgs", "suzuki-shunsuke", "tfcmt", "pkg.yaml"), []byte(`packages:
+  - name: suzuki-shunsuke/tfcmt@v0.4.0
+  - name: suzuki-shunsuke/tfcmt
+    version: v1.0.0
+  - name: suzuki-shunsuke/tfcmt@v1.5.0
+  - name: suzuki-shunsuke/tfcmt@v2.0.0
+  - name: suzuki-shunsuke/tfcmt@v3.1.0
+`), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Format: lint.FormatText,
+		Stdout: buf,
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	exp := `pkgs/suzuki-shunsuke/tfcmt/registry.yaml:10:29: warning: version_overrides[1] is unreachable because version_overrides[0] covers all versions matching it (e.g. v0.4.0) (unreachable-version-override)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:6:25: warning: no version_constraint matches v1.0.0 - v1.5.0 (2 versions) (uncovered-versions)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:14:29: info: version_overrides[3] could be merged with version_overrides[2] because they are same except for version_constraint (mergeable-version-overrides)
+`
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("diagnostics (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Args:   []string{"suzuki-shunsuke/tfcmt"},
+		Format: lint.FormatText,
+		Stdout: buf,
+		Releases: &fakeReleaseLister{
+			tags: []string{"v4.0.0", "v3.0.0", "v0.1.0"},
+		},
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	exp = `pkgs/suzuki-shunsuke/tfcmt/registry.yaml:10:29: warning: version_overrides[1] is unreachable because version_overrides[0] covers all versions matching it (e.g. v0.1.0) (unreachable-version-override)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:16:29: warning: version_overrides[4] matches no version (unreachable-version-override)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:6:25: warning: no version_constraint matches v1.0.0 - v1.5.0 (2 versions) (uncovered-versions)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:6:25: warning: no version_constraint matches v4.0.0 (uncovered-versions)
+pkgs/suzuki-shunsuke/tfcmt/registry.yaml:14:29: info: version_overrides[3] could be merged with version_overrides[2] because they are same except for version_constraint (mergeable-version-overrides)
+`
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_info(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writePkg(t, "suzuki-shunsuke/tfcmt", `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.tar.gz
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: semver("< 1.0.0")
+        asset: tfcmt.tar.gz
+      - version_constraint: "true"
+        asset: tfcmt.tar.gz
+`)
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Format: lint.FormatText,
+		Stdout: buf,
+	}); err != nil {
+		t.Fatalf("info violations must not make Run fail: %v", err)
+	}
+	exp := `pkgs/suzuki-shunsuke/tfcmt/registry.yaml:10:29: info: version_overrides[1] could be merged with version_overrides[0] because they are same except for version_constraint (mergeable-version-overrides)
+`
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_aliases(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writePkg(t, "tfcmt/tfcmt", `packages:
+  - type: github_release
+    repo_owner: tfcmt
+    repo_name: tfcmt
+    aliases:
+      - name: suzuki-shunsuke/tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.tar.gz
+    version_constraint: semver(">= 1.0.0")
+`)
+	if err := os.WriteFile(filepath.Join("pkgs", "tfcmt", "tfcmt", "pkg.yaml"), []byte(`packages:
+  - name: suzuki-shunsuke/tfcmt@v0.4.0
+  - name: tfcmt/tfcmt@v1.0.0
+  - name: cli/cli@v0.1.0
+`), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	buf := &strings.Builder{}
+	if err := lint.Run(t.Context(), slog.New(slog.DiscardHandler), &lint.Config{
+		Format: lint.FormatText,
+		Stdout: buf,
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	exp := `pkgs/tfcmt/tfcmt/registry.yaml:8:25: warning: no version_constraint matches v0.4.0 (uncovered-versions)
+`
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("diagnostics (-want +got):\n%s", diff)
+	}
+}
@@ -2,10 +2,15 @@ package mv
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 
+	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
+	"github.com/aquaproj/registry-tool/pkg/git"
 	"github.com/spf13/afero"
 )
 
@@ -14,41 +19,248 @@ const (
 	filePermission os.FileMode = 0o644
 )
 
-func Move(_ context.Context, afs afero.Fs, oldPackageName, newPackageName string) error {
-	// Check if the old package exists
+// Options are options of Move.
+type Options struct {
+	// KeepRepo keeps repo_owner and repo_name.
+	// The field name is set to the new package name instead.
+	KeepRepo bool
+	// RepoOwner and RepoName are the new repository.
+	// If they are empty, they are the first two segments of the new package name.
+	// If the package name doesn't change, only the repository is changed.
+	RepoOwner string
+	RepoName  string
+}
+
+// file is a file of the package moved from Src to Dst.
+type file struct {
+	Name string
+	Src  string
+	Dst  string
+	// Original is the content before the move.
+	Original []byte
+	// Content is the content after the move.
+	Content []byte
+	// Tracked is true if the file is tracked by git.
+	Tracked bool
+}
+
+// Move renames the package oldPackageName to newPackageName.
+// Files of the package are moved to the new directory, and registry.yaml and pkg.yaml are edited.
+// All edits are staged in memory first, so nothing is changed if any edit fails.
+// Tracked files are moved by git mv so that their history follows them.
+// Empty directories of the old package are removed, and registry.yaml in the repository root is regenerated.
+// If moving files or regenerating registry.yaml fails, moved files and generated files are restored.
+// Move fails if the new package already exists or the new package name is already a name or an alias of another package.
+// If the package name doesn't change, only repo_owner and repo_name are changed to opts.RepoOwner and opts.RepoName.
+func Move(ctx context.Context, logger *slog.Logger, repo git.Repo, oldPackageName, newPackageName string, opts *Options) error {
+	if oldPackageName == newPackageName && opts.RepoOwner == "" {
+		return errors.New("the new package name must be different from the old package name")
+	}
 	oldPkgPath := filepath.Join("pkgs", filepath.FromSlash(oldPackageName))
 	newPkgPath := filepath.Join("pkgs", filepath.FromSlash(newPackageName))
-	// Create directories
-	if err := afs.MkdirAll(newPkgPath, dirPermission); err != nil {
-		return fmt.Errorf("create directories for new package: %w", err)
+	if oldPackageName != newPackageName {
+		if err := checkNotExist(ctx, newPkgPath, oldPackageName, newPackageName); err != nil {
+			return err
+		}
+	}
+	files, err := stage(ctx, repo, oldPkgPath, newPkgPath, oldPackageName, newPackageName, opts)
+	if err != nil {
+		return err
 	}
-	newPkgYAMLPath := filepath.Join(newPkgPath, "pkg.yaml")
-	newRegistryYAMLPath := filepath.Join(newPkgPath, "registry.yaml")
-	newScaffoldYAMLPath := filepath.Join(newPkgPath, "scaffold.yaml")
-	// Move files
-	if err := afs.Rename(filepath.Join(oldPkgPath, "pkg.yaml"), newPkgYAMLPath); err != nil {
-		return fmt.Errorf("move pkg.yaml: %w", err)
+	genOpts, err := genrg.OptionsFromConfig()
+	if err != nil {
+		return err //nolint:wrapcheck
 	}
-	if err := afs.Rename(filepath.Join(oldPkgPath, "registry.yaml"), newRegistryYAMLPath); err != nil {
-		return fmt.Errorf("move registry.yaml: %w", err)
+	outputs, err := readOutputs(genOpts.Paths())
+	if err != nil {
+		return err
 	}
-	oldScaffoldYAMLPath := filepath.Join(oldPkgPath, "scaffold.yaml")
-	if f, err := afero.Exists(afs, oldScaffoldYAMLPath); err != nil {
-		return fmt.Errorf("check if scaffold.yaml exists: %w", err)
-	} else if f {
-		if err := afs.Rename(oldScaffoldYAMLPath, newScaffoldYAMLPath); err != nil {
-			return fmt.Errorf("move scaffold.yaml: %w", err)
+	moved, err := apply(ctx, repo, newPkgPath, files)
+	if err == nil {
+		err = regenerate(ctx, genOpts, oldPkgPath)
+	}
+	if err != nil {
+		rollback(ctx, logger, repo, oldPkgPath, newPkgPath, moved, outputs)
+		return err
+	}
+	return nil
+}
+
+// checkNotExist returns an error if the new package exists,
+// or if the new package name is already a name or an alias of another package.
+func checkNotExist(ctx context.Context, pkgPath, oldPackageName, newPackageName string) error {
+	for _, name := range []string{"registry.yaml", "pkg.yaml"} {
+		if _, err := os.Stat(filepath.Join(pkgPath, name)); err == nil {
+			return fmt.Errorf("the package %s already exists", newPackageName)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("check if the new package exists: %w", err)
 		}
 	}
+	if err := genrg.CheckNewName(ctx, newPackageName, oldPackageName); err != nil {
+		return fmt.Errorf("check the new package name: %w", err)
+	}
+	return nil
+}
 
-	// Fix repo_owner and repo_name in registry.yaml
-	// Add aliases in registry.yaml
-	if err := editRegistry(afs, newRegistryYAMLPath, oldPackageName, newPackageName); err != nil {
-		return err
+// stage reads files of the package and edits them in memory.
+func stage(ctx context.Context, repo git.Repo, oldPkgPath, newPkgPath, oldPackageName, newPackageName string, opts *Options) ([]*file, error) { //nolint:cyclop
+	tracked, err := repo.LsFiles(ctx, filepath.ToSlash(oldPkgPath))
+	if err != nil {
+		return nil, fmt.Errorf("list tracked files of the package: %w", err)
+	}
+	// Edits are written to memory, and files on disk aren't changed.
+	mem := afero.NewMemMapFs()
+	files := []*file{}
+	for _, name := range []string{"pkg.yaml", "registry.yaml", "scaffold.yaml"} {
+		src := filepath.Join(oldPkgPath, name)
+		b, err := os.ReadFile(src)
+		if err != nil {
+			if name == "scaffold.yaml" && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		dst := filepath.Join(newPkgPath, name)
+		if err := afero.WriteFile(mem, dst, b, filePermission); err != nil {
+			return nil, fmt.Errorf("stage %s: %w", name, err)
+		}
+		files = append(files, &file{
+			Name:     name,
+			Src:      src,
+			Dst:      dst,
+			Original: b,
+			Tracked:  slices.Contains(tracked, filepath.ToSlash(src)),
+		})
+	}
+
+	// Fix name, repo_owner, repo_name, files, and aliases in registry.yaml
+	if err := editRegistry(mem, filepath.Join(newPkgPath, "registry.yaml"), oldPackageName, newPackageName, opts); err != nil {
+		return nil, err
 	}
 	// Fix package names in pkg.yaml
-	if err := editPackageYAML(afs, newPkgYAMLPath, oldPackageName, newPackageName); err != nil {
+	if err := editPackageYAML(mem, filepath.Join(newPkgPath, "pkg.yaml"), oldPackageName, newPackageName); err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		b, err := afero.ReadFile(mem, f.Dst)
+		if err != nil {
+			return nil, fmt.Errorf("read staged %s: %w", f.Name, err)
+		}
+		f.Content = b
+	}
+	return files, nil
+}
+
+// output is a file generated by genrg.GenerateRegistry.
+type output struct {
+	Path string
+	// Content is the content before the move. It's nil if the file doesn't exist.
+	Content []byte
+}
+
+// readOutputs reads files generated by genrg.GenerateRegistry to restore them.
+func readOutputs(paths []string) ([]*output, error) {
+	outputs := make([]*output, len(paths))
+	for i, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("read %s: %w", p, err)
+		}
+		outputs[i] = &output{Path: p, Content: b}
+	}
+	return outputs, nil
+}
+
+// apply moves files and writes staged contents.
+// It returns moved files to be restored even if it fails.
+func apply(ctx context.Context, repo git.Repo, newPkgPath string, files []*file) ([]*file, error) {
+	if err := os.MkdirAll(newPkgPath, dirPermission); err != nil {
+		return nil, fmt.Errorf("create directories for new package: %w", err)
+	}
+	var moved []*file
+	for _, f := range files {
+		if err := moveFile(ctx, repo, f.Src, f.Dst, f.Tracked); err != nil {
+			return moved, fmt.Errorf("move %s: %w", f.Name, err)
+		}
+		moved = append(moved, f)
+	}
+	for _, f := range files {
+		if err := os.WriteFile(f.Dst, f.Content, filePermission); err != nil {
+			return moved, fmt.Errorf("write %s: %w", f.Name, err)
+		}
+	}
+	return moved, nil
+}
+
+// regenerate removes empty directories of the old package and regenerates registry.yaml.
+func regenerate(ctx context.Context, genOpts *genrg.Options, oldPkgPath string) error {
+	if err := removeEmptyDirs(oldPkgPath); err != nil {
 		return err
 	}
+	if err := genrg.GenerateRegistry(ctx, genOpts); err != nil {
+		return fmt.Errorf("generate registry.yaml: %w", err)
+	}
+	return nil
+}
+
+// rollback restores moved files and files generated by genrg.GenerateRegistry.
+// Errors are logged so that as many files as possible are restored.
+func rollback(ctx context.Context, logger *slog.Logger, repo git.Repo, oldPkgPath, newPkgPath string, moved []*file, outputs []*output) {
+	if err := os.MkdirAll(oldPkgPath, dirPermission); err != nil {
+		logger.Error("create directories of the old package", "error", err)
+	}
+	for _, f := range slices.Backward(moved) {
+		if err := moveFile(ctx, repo, f.Dst, f.Src, f.Tracked); err != nil {
+			logger.Error("restore a file", "file", f.Src, "error", err)
+			continue
+		}
+		if err := os.WriteFile(f.Src, f.Original, filePermission); err != nil {
+			logger.Error("restore a file", "file", f.Src, "error", err)
+		}
+	}
+	if err := removeEmptyDirs(newPkgPath); err != nil {
+		logger.Error("remove directories of the new package", "error", err)
+	}
+	for _, o := range outputs {
+		if o.Content == nil {
+			if err := os.Remove(o.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				logger.Error("remove a generated file", "file", o.Path, "error", err)
+			}
+			continue
+		}
+		if err := os.WriteFile(o.Path, o.Content, filePermission); err != nil {
+			logger.Error("restore a generated file", "file", o.Path, "error", err)
+		}
+	}
+}
+
+func moveFile(ctx context.Context, repo git.Repo, src, dst string, tracked bool) error {
+	if src == dst {
+		return nil
+	}
+	if tracked {
+		return repo.Move(ctx, filepath.ToSlash(src), filepath.ToSlash(dst)) //nolint:wrapcheck
+	}
+	return os.Rename(src, dst) //nolint:wrapcheck
+}
+
+// removeEmptyDirs removes dir and its parent directories under pkgs if they are empty.
+func removeEmptyDirs(dir string) error {
+	for ; dir != "pkgs" && dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("check if the directory is empty: %w", err)
+		}
+		if len(entries) > 0 {
+			return nil
+		}
+		if err := os.Remove(dir); err != nil {
+			return fmt.Errorf("remove the empty directory %s: %w", dir, err)
+		}
+	}
 	return nil
 }
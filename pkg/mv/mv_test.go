@@ -0,0 +1,157 @@
+package mv_test
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/mv"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newRepo creates a repository having the package foo/bar in the current directory.
+func newRepo(t *testing.T, registryYAML string) git.Repo {
+	t.Helper()
+	testutil.SetGitEnv(t)
+	t.Chdir(t.TempDir())
+	testutil.Git(t, "", "init", "-b", "main")
+	testutil.WriteFile(t, "pkgs/foo/bar/registry.yaml", registryYAML)
+	testutil.WriteFile(t, "pkgs/foo/bar/pkg.yaml", "packages:\n  - name: foo/bar@v1.0.0\n")
+	testutil.Git(t, "", "add", ".")
+	testutil.Git(t, "", "commit", "-m", "init")
+	// scaffold.yaml isn't tracked
+	testutil.WriteFile(t, "pkgs/foo/bar/scaffold.yaml", "name: foo/bar\n")
+	return git.New(slog.New(slog.DiscardHandler), "")
+}
+
+func TestMove(t *testing.T) {
+	repo := newRepo(t, `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    asset: bar.tar.gz
+`)
+	if err := mv.Move(t.Context(), slog.New(slog.DiscardHandler), repo, "foo/bar", "baz/bar", &mv.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	exp := `packages:
+  - type: github_release
+    repo_owner: baz
+    repo_name: bar
+    aliases:
+      - name: foo/bar
+    asset: bar.tar.gz
+`
+	if diff := cmp.Diff(exp, testutil.ReadFile(t, "pkgs/baz/bar/registry.yaml")); diff != "" {
+		t.Fatalf("registry.yaml (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("packages:\n  - name: baz/bar@v1.0.0\n", testutil.ReadFile(t, "pkgs/baz/bar/pkg.yaml")); diff != "" {
+		t.Fatalf("pkg.yaml (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("name: foo/bar\n", testutil.ReadFile(t, "pkgs/baz/bar/scaffold.yaml")); diff != "" {
+		t.Fatalf("scaffold.yaml (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat("pkgs/foo"); !os.IsNotExist(err) {
+		t.Fatalf("the old directory must be removed: %v", err)
+	}
+	if s := testutil.ReadFile(t, "registry.yaml"); !strings.Contains(s, "repo_owner: baz") {
+		t.Fatalf("registry.yaml must be regenerated: %s", s)
+	}
+	// git mv stages renames, and edits aren't staged
+	exp = "RM pkgs/foo/bar/pkg.yaml -> pkgs/baz/bar/pkg.yaml"
+	if s := testutil.Git(t, "", "status", "--short"); !strings.Contains(s, exp) {
+		t.Fatalf("pkg.yaml must be moved by git mv: %s", s)
+	}
+}
+
+func TestMove_rollback(t *testing.T) {
+	registryYAML := `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: qux
+`
+	repo := newRepo(t, registryYAML)
+	if err := mv.Move(t.Context(), slog.New(slog.DiscardHandler), repo, "foo/bar", "baz/bar", &mv.Options{}); err == nil {
+		t.Fatal("error should be returned because the package isn't found in registry.yaml")
+	}
+	if diff := cmp.Diff(registryYAML, testutil.ReadFile(t, "pkgs/foo/bar/registry.yaml")); diff != "" {
+		t.Fatalf("registry.yaml must not be changed (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat("pkgs/baz"); !os.IsNotExist(err) {
+		t.Fatalf("the new directory must not be created: %v", err)
+	}
+	if s := testutil.Git(t, "", "status", "--short"); s != "?? pkgs/foo/bar/scaffold.yaml" {
+		t.Fatalf("the working tree must not be changed: %s", s)
+	}
+}
+
+func TestMove_rollbackRegenerate(t *testing.T) {
+	registryYAML := `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+`
+	repo := newRepo(t, registryYAML)
+	// registry.yaml can't be regenerated because the other package is invalid
+	testutil.WriteFile(t, "pkgs/other/qux/registry.yaml", "packages:\n  - type: github_release\n    repo_owner: other\n    repo_name: quux\n")
+	if err := mv.Move(t.Context(), slog.New(slog.DiscardHandler), repo, "foo/bar", "baz/bar", &mv.Options{}); err == nil {
+		t.Fatal("error should be returned because registry.yaml can't be regenerated")
+	}
+	if diff := cmp.Diff(registryYAML, testutil.ReadFile(t, "pkgs/foo/bar/registry.yaml")); diff != "" {
+		t.Fatalf("registry.yaml must be restored (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("name: foo/bar\n", testutil.ReadFile(t, "pkgs/foo/bar/scaffold.yaml")); diff != "" {
+		t.Fatalf("scaffold.yaml must be restored (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat("pkgs/baz"); !os.IsNotExist(err) {
+		t.Fatalf("the new directory must be removed: %v", err)
+	}
+	if _, err := os.Stat("registry.yaml"); !os.IsNotExist(err) {
+		t.Fatalf("registry.yaml in the repository root must not be generated: %v", err)
+	}
+	if s := testutil.Git(t, "", "status", "--short"); s != "?? pkgs/foo/bar/scaffold.yaml\n?? pkgs/other/" {
+		t.Fatalf("the working tree must be restored: %s", s)
+	}
+}
+
+func TestMove_exist(t *testing.T) {
+	registryYAML := `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+`
+	repo := newRepo(t, registryYAML)
+	testutil.WriteFile(t, "pkgs/baz/bar/registry.yaml", "packages: []\n")
+	if err := mv.Move(t.Context(), slog.New(slog.DiscardHandler), repo, "foo/bar", "baz/bar", &mv.Options{}); err == nil {
+		t.Fatal("error should be returned because the new package already exists")
+	}
+	if diff := cmp.Diff(registryYAML, testutil.ReadFile(t, "pkgs/foo/bar/registry.yaml")); diff != "" {
+		t.Fatalf("registry.yaml must not be changed (-want +got):\n%s", diff)
+	}
+}
+
+func TestMove_nameUsed(t *testing.T) {
+	registryYAML := `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+`
+	repo := newRepo(t, registryYAML)
+	testutil.WriteFile(t, "pkgs/qux/bar/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: qux
+    repo_name: bar
+    aliases:
+      - name: Baz/bar
+`)
+	err := mv.Move(t.Context(), slog.New(slog.DiscardHandler), repo, "foo/bar", "baz/bar", &mv.Options{})
+	if err == nil || !strings.Contains(err.Error(), `alias "Baz/bar" of qux/bar`) {
+		t.Fatalf("error should be returned because the new name is an alias of another package: %v", err)
+	}
+	if diff := cmp.Diff(registryYAML, testutil.ReadFile(t, "pkgs/foo/bar/registry.yaml")); diff != "" {
+		t.Fatalf("registry.yaml must not be changed (-want +got):\n%s", diff)
+	}
+}
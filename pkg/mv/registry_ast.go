@@ -3,10 +3,12 @@ package mv
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	wast "github.com/aquaproj/aqua/v2/pkg/ast"
 	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/format"
 	goccyYAML "github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/parser"
@@ -14,7 +16,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func editRegistry(afs afero.Fs, newRegistryYAMLPath, oldPackageName, newPackageName string) error { //nolint:cyclop
+func editRegistry(afs afero.Fs, newRegistryYAMLPath, oldPackageName, newPackageName string, opts *Options) error {
 	b, err := afero.ReadFile(afs, newRegistryYAMLPath)
 	if err != nil {
 		return fmt.Errorf("open a registry.yaml: %w", err)
@@ -24,9 +26,11 @@ func editRegistry(afs afero.Fs, newRegistryYAMLPath, oldPackageName, newPackageN
 	if err := yaml.Unmarshal(b, cfg); err != nil {
 		return fmt.Errorf("unmarshal registry.yaml as YAML: %w", err)
 	}
-	pkg, err := getPackageFromConfig(cfg, oldPackageName)
-	if err != nil {
-		return err
+	idx := slices.IndexFunc(cfg.PackageInfos, func(pkgInfo *registry.PackageInfo) bool {
+		return pkgInfo.GetName() == oldPackageName
+	})
+	if idx == -1 {
+		return errors.New("package isn't found in registry.yaml")
 	}
 
 	file, err := parser.ParseBytes(b, parser.ParseComments)
@@ -42,61 +46,196 @@ func editRegistry(afs afero.Fs, newRegistryYAMLPath, oldPackageName, newPackageN
 	if !ok {
 		return errors.New("the value must be a sequence node")
 	}
-	for _, value := range seq.Values {
-		idx, up, err := parseRegistryNode(value, oldPackageName, newPackageName, pkg)
-		if err != nil {
+	m, ok := seq.Values[idx].(*ast.MappingNode)
+	if !ok {
+		return errors.New("the package must be a mapping node")
+	}
+	if err := editPackageInfo(m, cfg.PackageInfos[idx], oldPackageName, newPackageName, opts); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(afs, newRegistryYAMLPath, []byte(file.String()), filePermission); err != nil {
+		return fmt.Errorf("write registry.yaml: %w", err)
+	}
+	return nil
+}
+
+// newRepo returns repo_owner and repo_name of the new package.
+// It returns false if repo_owner and repo_name shouldn't be changed.
+// opts.RepoOwner and opts.RepoName take precedence over the new package name.
+// The first segment of a domain-style name like example.com/foo/bar isn't a GitHub user,
+// so such a name doesn't change them.
+func newRepo(pkg *registry.PackageInfo, newPackageName string, opts *Options) (string, string, bool) {
+	if opts.KeepRepo || !pkg.HasRepo() {
+		return "", "", false
+	}
+	if opts.RepoOwner != "" && opts.RepoName != "" {
+		return opts.RepoOwner, opts.RepoName, true
+	}
+	owner, rest, ok := strings.Cut(newPackageName, "/")
+	if !ok || strings.Contains(owner, ".") {
+		return "", "", false
+	}
+	repoName, _, _ := strings.Cut(rest, "/")
+	return owner, repoName, true
+}
+
+// editPackageInfo renames the package in the mapping node m.
+// The name field is added if the new name isn't derived from repo_owner and repo_name,
+// and the files field is added if the default command name changes.
+// The old name is added to aliases and the new name is removed from aliases.
+func editPackageInfo(m *ast.MappingNode, pkg *registry.PackageInfo, oldPackageName, newPackageName string, opts *Options) error { //nolint:cyclop
+	newPkg := pkg.Copy()
+	if owner, repoName, ok := newRepo(pkg, newPackageName, opts); ok {
+		if err := setString(m, "repo_owner", owner); err != nil {
+			return err
+		}
+		if err := setString(m, wordRepoName, repoName); err != nil {
+			return err
+		}
+		newPkg.RepoOwner = owner
+		newPkg.RepoName = repoName
+	}
+	switch {
+	case pkg.Name != "":
+		if err := setString(m, wordName, newPackageName); err != nil {
+			return err
+		}
+		newPkg.Name = newPackageName
+	case newPkg.GetName() != newPackageName:
+		if err := insertKey(m, wordName, newPackageName); err != nil {
 			return err
 		}
-		if idx != 0 {
-			// Insert aliases
-			if err := insertAliases(value, idx, oldPackageName); err != nil {
+		newPkg.Name = newPackageName
+	}
+
+	// Keep the command name
+	if len(pkg.Files) == 0 {
+		oldFiles := pkg.GetFiles()
+		newFiles := newPkg.GetFiles()
+		if len(oldFiles) == 1 && (len(newFiles) != 1 || newFiles[0].Name != oldFiles[0].Name) {
+			if err := insertKey(m, "files", []*registry.File{{Name: oldFiles[0].Name}}); err != nil {
 				return err
 			}
-			up = true
 		}
-		if up {
-			if err := afero.WriteFile(afs, newRegistryYAMLPath, []byte(file.String()), 0o644); err != nil { //nolint:mnd
-				return fmt.Errorf("write registry.yaml: %w", err)
+	}
+
+	if oldPackageName == newPackageName {
+		return nil
+	}
+	return editAliases(m, pkg, oldPackageName, newPackageName)
+}
+
+func editAliases(m *ast.MappingNode, pkg *registry.PackageInfo, oldPackageName, newPackageName string) error {
+	old := &registry.Alias{Name: oldPackageName}
+	mvn := findKey(m, "aliases")
+	if mvn == nil {
+		return insertKey(m, "aliases", []*registry.Alias{old})
+	}
+	// The new name isn't an alias anymore
+	if seq, ok := mvn.Value.(*ast.SequenceNode); ok {
+		for i := len(seq.Values) - 1; i >= 0; i-- {
+			name := findKey(seq.Values[i], wordName)
+			if name == nil {
+				continue
+			}
+			if sn, ok := name.Value.(*ast.StringNode); !ok || sn.Value != newPackageName {
+				continue
+			}
+			seq.Values = slices.Delete(seq.Values, i, i+1)
+			if len(seq.ValueHeadComments) > i {
+				seq.ValueHeadComments = slices.Delete(seq.ValueHeadComments, i, i+1)
 			}
-			return nil
 		}
 	}
+	if slices.ContainsFunc(pkg.Aliases, func(alias *registry.Alias) bool {
+		return alias.Name == oldPackageName
+	}) {
+		return nil
+	}
+	node, err := goccyYAML.ValueToNode([]*registry.Alias{old})
+	if err != nil {
+		return fmt.Errorf("convert an alias to node: %w", err)
+	}
+	if err := appendNode(mvn, node); err != nil {
+		return fmt.Errorf("append the old package to aliases: %w", err)
+	}
 	return nil
 }
 
-func insertAliases(value ast.Node, idx int, oldPackageName string) error {
-	// Insert aliases
-	mv, ok := value.(*ast.MappingNode)
-	if !ok {
-		return errors.New("value must be a mapping node")
+func findKey(node ast.Node, key string) *ast.MappingValueNode {
+	mvs, err := wast.NormalizeMappingValueNodes(node)
+	if err != nil {
+		return nil
 	}
+	for _, mvn := range mvs {
+		if mvn.Key.String() == key {
+			return mvn
+		}
+	}
+	return nil
+}
 
-	f, err := parser.ParseBytes([]byte("aliases:\n  - name: "+oldPackageName), parser.ParseComments)
-	if err != nil {
-		return fmt.Errorf("parse text as YAML: %w", err)
+func setString(m *ast.MappingNode, key, value string) error {
+	mvn := findKey(m, key)
+	if mvn == nil {
+		return nil
 	}
-	mn, ok := f.Docs[0].Body.(*ast.MappingNode)
+	sn, ok := mvn.Value.(*ast.StringNode)
 	if !ok {
-		return errors.New("body must be a mapping node")
+		return fmt.Errorf("%s must be a string", key)
 	}
-
-	latterValues := make([]*ast.MappingValueNode, len(mv.Values[idx:]))
-	copy(latterValues, mv.Values[idx:])
-	mv.Values = mv.Values[:idx]
-	mv.Merge(mn)
-	mv.Merge(&ast.MappingNode{
-		Values: latterValues,
-	})
+	sn.Value = value
 	return nil
 }
 
-func getPackageFromConfig(cfg *registry.Config, name string) (*registry.PackageInfo, error) {
-	for _, pkgInfo := range cfg.PackageInfos {
-		if name == pkgInfo.GetName() {
-			return pkgInfo, nil
+// insertIndex returns the index of the mapping node where the key is inserted.
+// aliases are put after name if it exists, otherwise after repo_name.
+// Other keys are put in the canonical order of keys.
+func insertIndex(m *ast.MappingNode, key string) int {
+	if key == "aliases" {
+		for _, prev := range []string{wordName, wordRepoName} {
+			if idx := slices.IndexFunc(m.Values, func(mvn *ast.MappingValueNode) bool {
+				return mvn.Key.String() == prev
+			}); idx != -1 {
+				return idx + 1
+			}
 		}
+		return 0
+	}
+	rank := format.PackageKeyRank(key)
+	if idx := slices.IndexFunc(m.Values, func(mvn *ast.MappingValueNode) bool {
+		return format.PackageKeyRank(mvn.Key.String()) > rank
+	}); idx != -1 {
+		return idx
 	}
-	return nil, errors.New("package isn't found in registry.yaml")
+	return len(m.Values)
+}
+
+// insertKey inserts the key to the mapping node at the index returned by insertIndex.
+func insertKey(m *ast.MappingNode, key string, value any) error {
+	b, err := goccyYAML.MarshalWithOptions(map[string]any{key: value}, goccyYAML.IndentSequence(true))
+	if err != nil {
+		return fmt.Errorf("marshal %s as YAML: %w", key, err)
+	}
+	f, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parse text as YAML: %w", err)
+	}
+	mvs, err := wast.NormalizeMappingValueNodes(f.Docs[0].Body)
+	if err != nil || len(mvs) != 1 {
+		return errors.New("body must be a mapping node")
+	}
+	if len(m.Values) > 0 {
+		mvs[0].AddColumn(m.Values[0].Key.GetToken().Position.Column - mvs[0].Key.GetToken().Position.Column)
+	}
+	idx := insertIndex(m, key)
+	if idx == 0 && len(m.Values) > 0 {
+		// The comment of the first key is output before "- ", so it's kept at the head.
+		mvs[0].Comment = m.Values[0].Comment
+		m.Values[0].Comment = nil
+	}
+	m.Values = slices.Insert(m.Values, idx, mvs[0])
+	return nil
 }
 
 func appendNode(mapValue *ast.MappingValueNode, node ast.Node) error {
@@ -115,86 +254,3 @@ func appendNode(mapValue *ast.MappingValueNode, node ast.Node) error {
 }
 
 const wordRepoName = "repo_name"
-
-func parseRegistryNode(node ast.Node, oldPackageName, newPackageName string, pkg *registry.PackageInfo) (int, bool, error) { //nolint:funlen,cyclop,gocognit
-	mvs, err := wast.NormalizeMappingValueNodes(node)
-	if err != nil {
-		return 0, false, fmt.Errorf("normalize mapping value nodes: %w", err)
-	}
-	var newRepoOwner string
-	var newRepoName string
-	arr := strings.Split(newPackageName, "/")
-	if len(arr) > 1 {
-		newRepoOwner = arr[0]
-		newRepoName = arr[1]
-	}
-	updated := false
-	prevField := ""
-	if pkg.Aliases == nil {
-		if pkg.Name != "" {
-			prevField = wordName
-		} else if pkg.RepoName != "" {
-			prevField = wordRepoName
-		}
-	}
-	insertIdx := 0
-	for i, mvn := range mvs {
-		key, ok := mvn.Key.(*ast.StringNode)
-		if !ok {
-			continue
-		}
-		switch key.Value {
-		case wordName:
-			sn, ok := mvn.Value.(*ast.StringNode)
-			if !ok {
-				return 0, false, errors.New("name must be a string")
-			}
-			if sn.Value != oldPackageName {
-				return 0, false, nil
-			}
-			sn.Value = newPackageName
-			updated = true
-			if prevField == wordName {
-				insertIdx = i + 1
-			}
-		case "repo_owner":
-			sn, ok := mvn.Value.(*ast.StringNode)
-			if !ok {
-				return 0, false, errors.New("name must be a string")
-			}
-			if sn.Value != newRepoOwner {
-				sn.Value = newRepoOwner
-				updated = true
-			}
-		case wordRepoName:
-			sn, ok := mvn.Value.(*ast.StringNode)
-			if !ok {
-				return 0, false, errors.New("name must be a string")
-			}
-			if sn.Value != newRepoName {
-				sn.Value = newRepoName
-				updated = true
-			}
-			if prevField == wordRepoName {
-				insertIdx = i + 1
-			}
-		case "aliases":
-			// Add oldPackageName
-			aliases := []*registry.Alias{
-				{
-					Name: oldPackageName,
-				},
-			}
-			node, err := goccyYAML.ValueToNode(aliases)
-			if err != nil {
-				return 0, false, fmt.Errorf("convert an alias to node: %w", err)
-			}
-			if err := appendNode(mvn, node); err != nil {
-				return 0, false, fmt.Errorf("append the old package to aliases: %w", err)
-			}
-		default:
-			continue // Ignore unknown fields
-		}
-	}
-	return insertIdx, updated, nil
-}
@@ -0,0 +1,205 @@
+package mv
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestEditRegistry(t *testing.T) { //nolint:funlen
+	t.Parallel()
+	data := []struct {
+		name    string
+		src     string
+		oldName string
+		newName string
+		opts    *Options
+		exp     string
+		isErr   bool
+	}{
+		{
+			name: "transferred",
+			src: `packages:
+  # comment
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    aliases:
+      - name: baz/bar # new
+      - name: old/bar
+`,
+			oldName: "foo/bar",
+			newName: "baz/bar",
+			opts:    &Options{},
+			exp: `packages:
+  # comment
+  - type: github_release
+    repo_owner: baz
+    repo_name: bar
+    aliases:
+      - name: old/bar
+      - name: foo/bar
+`,
+		},
+		{
+			name: "renamed repository",
+			src: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    asset: bar.tar.gz
+`,
+			oldName: "foo/bar",
+			newName: "foo/qux",
+			opts:    &Options{},
+			exp: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: qux
+    aliases:
+      - name: foo/bar
+    asset: bar.tar.gz
+    files:
+      - name: bar
+`,
+		},
+		{
+			name: "multi-segment name",
+			src: `packages:
+  - name: foo/bar/cli
+    type: github_release
+    repo_owner: foo
+    repo_name: bar
+    files:
+      - name: cli
+`,
+			oldName: "foo/bar/cli",
+			newName: "baz/qux/cli",
+			opts:    &Options{},
+			exp: `packages:
+  - name: baz/qux/cli
+    aliases:
+      - name: foo/bar/cli
+    type: github_release
+    repo_owner: baz
+    repo_name: qux
+    files:
+      - name: cli
+`,
+		},
+		{
+			name: "add name",
+			src: `packages:
+  # comment
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+`,
+			oldName: "foo/bar",
+			newName: "foo/bar/bar",
+			opts:    &Options{},
+			exp: `packages:
+  # comment
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    name: foo/bar/bar
+    aliases:
+      - name: foo/bar
+`,
+		},
+		{
+			name: "keep repo",
+			src: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+`,
+			oldName: "foo/bar",
+			newName: "baz/bar",
+			opts:    &Options{KeepRepo: true},
+			exp: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    name: baz/bar
+    aliases:
+      - name: foo/bar
+`,
+		},
+		{
+			name: "domain-style name",
+			src: `packages:
+  - name: kubernetes/kubectl
+    type: http
+    url: https://dl.k8s.io/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl
+`,
+			oldName: "kubernetes/kubectl",
+			newName: "kubernetes.io/kubectl",
+			opts:    &Options{},
+			exp: `packages:
+  - name: kubernetes.io/kubectl
+    aliases:
+      - name: kubernetes/kubectl
+    type: http
+    url: https://dl.k8s.io/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl
+`,
+		},
+		{
+			name: "only repository",
+			src: `packages:
+  - name: foo-cli
+    type: github_release
+    repo_owner: foo
+    repo_name: bar
+`,
+			oldName: "foo-cli",
+			newName: "foo-cli",
+			opts:    &Options{RepoOwner: "baz", RepoName: "qux"},
+			exp: `packages:
+  - name: foo-cli
+    type: github_release
+    repo_owner: baz
+    repo_name: qux
+`,
+		},
+		{
+			name: "not found",
+			src: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+`,
+			oldName: "foo/qux",
+			newName: "baz/qux",
+			opts:    &Options{},
+			isErr:   true,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			afs := afero.NewMemMapFs()
+			if err := afero.WriteFile(afs, "registry.yaml", []byte(d.src), filePermission); err != nil {
+				t.Fatal(err)
+			}
+			if err := editRegistry(afs, "registry.yaml", d.oldName, d.newName, d.opts); err != nil {
+				if d.isErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if d.isErr {
+				t.Fatal("error should be returned")
+			}
+			b, err := afero.ReadFile(afs, "registry.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(d.exp, string(b)); diff != "" {
+				t.Fatalf("registry.yaml (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/aquaproj/registry-tool/pkg/scaffold"
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/suzuki-shunsuke/slog-error/slogerr"
 )
 
 func Resolve(ctx context.Context, logger *slog.Logger, pkgName string) (string, error) {
@@ -15,14 +17,26 @@ func Resolve(ctx context.Context, logger *slog.Logger, pkgName string) (string,
 		return strings.TrimPrefix(pkgName, "https://github.com/"), nil
 	}
 
-	branch, err := scaffold.GetCurrentBranch(ctx, logger)
+	cfg, err := config.Read()
 	if err != nil {
-		return "", fmt.Errorf("get current branch: %w", err)
+		return "", fmt.Errorf("read the configuration file: %w", err)
 	}
 
-	if !strings.HasPrefix(branch, "feat/") {
-		return "", errors.New("current branch must be feat/<package name> or you must give a package name")
+	branch, err := git.New(logger, "").CurrentBranch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get current branch: %w", err)
 	}
 
-	return strings.TrimPrefix(branch, "feat/"), nil
+	pkgName, ok, err := cfg.Git.PackageNameFromBranch(branch)
+	if err != nil {
+		return "", fmt.Errorf("get a package name from the branch: %w", err)
+	}
+	if !ok {
+		return "", slogerr.With( //nolint:wrapcheck
+			errors.New("current branch doesn't match the branch template, so you must give a package name"),
+			"branch", branch,
+			"branch_template", cfg.Git.BranchTemplate,
+		)
+	}
+	return pkgName, nil
 }
@@ -10,7 +10,8 @@ import (
 
 	"github.com/aquaproj/aqua/v2/pkg/checksum"
 	"github.com/aquaproj/aqua/v2/pkg/config/registry"
-	"github.com/aquaproj/aqua/v2/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/github"
 	goccyYAML "github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/parser"
@@ -33,10 +34,15 @@ func PatchChecksum(ctx context.Context, logger *slog.Logger, configFilePath stri
 		return fmt.Errorf("parse configuration file as YAML: %w", err)
 	}
 
-	ghClient, err := github.New(ctx, logger)
+	argdCfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("read the configuration file: %w", err)
+	}
+	ghClient, err := github.New(ctx, logger, argdCfg.GitHub)
 	if err != nil {
 		return fmt.Errorf("create github client: %w", err)
 	}
+	defer ghClient.LogStats()
 	size := len(cfg.PackageInfos)
 	pkgsAST, err := GetPackagesAST(file)
 	if err != nil {
@@ -0,0 +1,106 @@
+// Package pkgfile finds, resolves, and writes files in the directory pkgs.
+package pkgfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/aquaproj/registry-tool/pkg/naming"
+)
+
+const (
+	// Dir is the directory including files of packages.
+	Dir = "pkgs"
+	// RegistryFile is the name of the registry file of a package.
+	RegistryFile = "registry.yaml"
+	// filePermission is the permission of new files.
+	filePermission os.FileMode = 0o644
+)
+
+// Find returns paths to files with the name in the directory pkgs sorted by path.
+func Find(name string) ([]string, error) {
+	var paths []string
+	if err := filepath.WalkDir(Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == name {
+			paths = append(paths, p)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("find %s in the directory pkgs: %w", name, err)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// Targets converts arguments to paths to pkgs/**/registry.yaml.
+// If args is empty, all pkgs/**/registry.yaml are returned.
+func Targets(ctx context.Context, logger *slog.Logger, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return Find(RegistryFile)
+	}
+	paths := make([]string, len(args))
+	for i, arg := range args {
+		p, err := Resolve(ctx, logger, arg)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = p
+	}
+	return paths, nil
+}
+
+// Resolve converts an argument to the path to registry.yaml.
+// A path to registry.yaml is returned as is, and a package name is resolved by naming.Resolve.
+func Resolve(ctx context.Context, logger *slog.Logger, arg string) (string, error) {
+	if path.Base(filepath.ToSlash(arg)) == RegistryFile {
+		return arg, nil
+	}
+	pkgName, err := naming.Resolve(ctx, logger, arg)
+	if err != nil {
+		return "", fmt.Errorf("resolve package name: %w", err)
+	}
+	return filepath.Join(Dir, filepath.FromSlash(pkgName), RegistryFile), nil
+}
+
+// Write writes data to the file atomically via a temporary file in the same directory.
+// The permission of the existing file is kept.
+func Write(p string, data []byte) error {
+	mode := filePermission
+	stat, err := os.Stat(p)
+	switch {
+	case err == nil:
+		mode = stat.Mode()
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("stat %s: %w", p, err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*")
+	if err != nil {
+		return fmt.Errorf("create a temporary file: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) //nolint:errcheck
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write a temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close a temporary file: %w", err)
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		return fmt.Errorf("change the permission of a temporary file: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("rename a temporary file to %s: %w", p, err)
+	}
+	return nil
+}
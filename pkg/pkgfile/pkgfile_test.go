@@ -0,0 +1,73 @@
+package pkgfile_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/pkgfile"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTargets(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", "packages: []\n")
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", "packages: []\n")
+	testutil.WriteFile(t, "pkgs/cli/cli/pkg.yaml", "packages: []\n")
+	logger := slog.New(slog.DiscardHandler)
+	paths, err := pkgfile.Targets(t.Context(), logger, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{
+		filepath.Join("pkgs", "cli", "cli", "registry.yaml"),
+		filepath.Join("pkgs", "suzuki-shunsuke", "tfcmt", "registry.yaml"),
+	}
+	if diff := cmp.Diff(exp, paths); diff != "" {
+		t.Fatalf("all registry.yaml (-want +got):\n%s", diff)
+	}
+	paths, err = pkgfile.Targets(t.Context(), logger, []string{"https://github.com/cli/cli", "pkgs/suzuki-shunsuke/tfcmt/registry.yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = []string{
+		filepath.Join("pkgs", "cli", "cli", "registry.yaml"),
+		"pkgs/suzuki-shunsuke/tfcmt/registry.yaml",
+	}
+	if diff := cmp.Diff(exp, paths); diff != "" {
+		t.Fatalf("resolved arguments (-want +got):\n%s", diff)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "registry.yaml")
+	if err := pkgfile.Write(p, []byte("packages: []\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(p, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := pkgfile.Write(p, []byte("packages: [{}]\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ReadFile(t, p); got != "packages: [{}]\n" {
+		t.Fatalf("unexpected content: %s", got)
+	}
+	stat, err := os.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != 0o600 {
+		t.Fatalf("the permission must be kept: %v", stat.Mode())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("temporary files must be removed: %v", entries)
+	}
+}
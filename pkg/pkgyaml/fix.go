@@ -0,0 +1,53 @@
+package pkgyaml
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/aquaproj/registry-tool/pkg/semver"
+)
+
+// Format returns the normalized pkg.yaml.
+// Duplicated entries are removed, and entries are sorted from newest to oldest.
+// Only the newest entry has the version in the name as `name@version` so that Renovate updates it,
+// and other entries have the field version.
+// Entries without versions are put at the end as they are.
+// pkg.yaml is rebuilt from entries, so comments and fields other than name and version are discarded.
+func (p *Package) Format() []byte {
+	var entries, noVersions []*Entry
+	for _, entry := range p.Entries {
+		if entry.Version == "" {
+			noVersions = append(noVersions, entry)
+			continue
+		}
+		if slices.ContainsFunc(entries, func(e *Entry) bool {
+			return e.Name == entry.Name && e.Version == entry.Version
+		}) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortStableFunc(entries, func(a, b *Entry) int {
+		switch {
+		case semver.GreaterThan(a.Version, b.Version):
+			return -1
+		case semver.GreaterThan(b.Version, a.Version):
+			return 1
+		default:
+			return 0
+		}
+	})
+	buf := &strings.Builder{}
+	buf.WriteString("packages:\n")
+	for i, entry := range entries {
+		if i == 0 {
+			buf.WriteString("  - name: " + entry.String() + "\n")
+			continue
+		}
+		buf.WriteString("  - name: " + entry.Name + "\n    version: " + entry.Version + "\n")
+	}
+	for _, entry := range noVersions {
+		buf.WriteString("  - name: " + entry.Name + "\n")
+	}
+	return []byte(buf.String())
+}
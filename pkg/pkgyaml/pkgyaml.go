@@ -0,0 +1,207 @@
+// Package pkgyaml checks consistency between pkgs/**/pkg.yaml and registry.yaml and fixes pkg.yaml.
+package pkgyaml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/constraint"
+	"github.com/aquaproj/registry-tool/pkg/semver"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// Entry is an entry of packages in pkg.yaml.
+type Entry struct {
+	// Name is the package name without the version.
+	Name    string
+	Version string
+	// Line is the line number of the entry.
+	Line int
+}
+
+func (e *Entry) String() string {
+	return e.Name + "@" + e.Version
+}
+
+// Problem is an inconsistency in pkg.yaml.
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// Package is a package having pkg.yaml.
+type Package struct {
+	Name string
+	// Path is the path to pkg.yaml.
+	Path    string
+	Info    *registry.PackageInfo
+	Entries []*Entry
+}
+
+type rawPkgYAML struct {
+	Packages []struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"packages"`
+}
+
+// Load reads pkgs/<package name>/pkg.yaml and registry.yaml.
+func Load(pkgName string) (*Package, error) {
+	pkgDir := filepath.Join(append([]string{"pkgs"}, strings.Split(pkgName, "/")...)...)
+	rgPath := filepath.Join(pkgDir, "registry.yaml")
+	rb, err := os.ReadFile(rgPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", rgPath, err)
+	}
+	cfg := &registry.Config{}
+	if err := yaml.Unmarshal(rb, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s as YAML: %w", rgPath, err)
+	}
+	if len(cfg.PackageInfos) != 1 {
+		return nil, fmt.Errorf("%s must include only one package", rgPath)
+	}
+	pkgPath := filepath.Join(pkgDir, "pkg.yaml")
+	b, err := os.ReadFile(pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", pkgPath, err)
+	}
+	entries, err := parseEntries(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", pkgPath, err)
+	}
+	return &Package{
+		Name:    pkgName,
+		Path:    filepath.ToSlash(pkgPath),
+		Info:    cfg.PackageInfos[0],
+		Entries: entries,
+	}, nil
+}
+
+func parseEntries(b []byte) ([]*Entry, error) {
+	raw := &rawPkgYAML{}
+	if err := yaml.Unmarshal(b, raw); err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	file, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	entries := make([]*Entry, len(raw.Packages))
+	for i, pkg := range raw.Packages {
+		name, version, ok := strings.Cut(pkg.Name, "@")
+		if !ok {
+			version = pkg.Version
+		}
+		entries[i] = &Entry{
+			Name:    name,
+			Version: version,
+			Line:    line(file, fmt.Sprintf("$.packages[%d].name", i)),
+		}
+	}
+	return entries, nil
+}
+
+// names returns the package name and aliases.
+func (p *Package) names() []string {
+	names := []string{p.Name}
+	for _, alias := range p.Info.Aliases {
+		names = append(names, alias.Name)
+	}
+	return names
+}
+
+// Check returns inconsistencies between pkg.yaml and registry.yaml.
+// Versions aren't checked if versions is nil.
+// Otherwise, versions are all versions of the package, and versions in pkg.yaml must be included in them.
+func (p *Package) Check(versions []string) []*Problem {
+	var problems []*Problem
+	add := func(line int, format string, a ...any) {
+		problems = append(problems, &Problem{
+			File:    p.Path,
+			Line:    line,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+	if len(p.Entries) == 0 {
+		add(1, "packages is empty")
+		return problems
+	}
+	names := p.names()
+	var seen []string
+	for _, entry := range p.Entries {
+		if !slices.Contains(names, entry.Name) {
+			add(entry.Line, "%s isn't the package name %s or its aliases", entry.Name, p.Name)
+		}
+		if entry.Version == "" {
+			add(entry.Line, "the version of %s isn't specified", entry.Name)
+			continue
+		}
+		if slices.Contains(seen, entry.String()) {
+			add(entry.Line, "%s is duplicated", entry)
+		}
+		seen = append(seen, entry.String())
+		if versions != nil && !slices.Contains(versions, entry.Version) {
+			add(entry.Line, "the version %s isn't found in %s", entry.Version, versionSourceName(p.Info))
+		}
+	}
+	if latest := p.latest(); latest != nil && !p.selectedByLastOverride(latest.Version) {
+		add(latest.Line, "the latest version %s isn't selected by the last version_overrides entry, so the entry isn't tested", latest.Version)
+	}
+	return problems
+}
+
+// latest returns the entry with the latest version.
+// It returns nil if no entry has a version.
+func (p *Package) latest() *Entry {
+	var latest *Entry
+	for _, entry := range p.Entries {
+		if entry.Version == "" {
+			continue
+		}
+		if latest == nil || semver.GreaterThan(entry.Version, latest.Version) {
+			latest = entry
+		}
+	}
+	return latest
+}
+
+// selectedByLastOverride reports whether the version is selected by the last version_overrides entry.
+// aqua gr puts the newest configuration to the last entry, so the latest version in pkg.yaml must select it to test it.
+// It returns true if the package has no version_overrides.
+func (p *Package) selectedByLastOverride(v string) bool {
+	vos := p.Info.VersionOverrides
+	if len(vos) == 0 {
+		return true
+	}
+	idx, ok := constraint.Select(p.Info, v)
+	return ok && idx == len(vos)-1
+}
+
+// line returns the line number of the node at the YAML path.
+// It returns 1 if the node isn't found.
+func line(file *ast.File, yamlPath string) int {
+	p, err := yaml.PathString(yamlPath)
+	if err != nil {
+		return 1
+	}
+	node, err := p.FilterFile(file)
+	if err != nil || node == nil {
+		return 1
+	}
+	tk := node.GetToken()
+	if tk == nil || tk.Position == nil {
+		return 1
+	}
+	return tk.Position.Line
+}
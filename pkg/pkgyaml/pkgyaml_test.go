@@ -0,0 +1,147 @@
+package pkgyaml_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/pkgyaml"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeVersionLister struct {
+	releases []string
+	tags     []string
+}
+
+func (f *fakeVersionLister) ListReleases(_ context.Context, _, _ string, _ *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	releases := make([]*github.RepositoryRelease, len(f.releases))
+	for i, tag := range f.releases {
+		releases[i] = &github.RepositoryRelease{
+			TagName: tag,
+		}
+	}
+	return releases, &github.Response{}, nil
+}
+
+func (f *fakeVersionLister) ListTags(_ context.Context, _, _ string, _ *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+	tags := make([]*github.RepositoryTag, len(f.tags))
+	for i, tag := range f.tags {
+		tags[i] = &github.RepositoryTag{
+			Name: &tag,
+		}
+	}
+	return tags, &github.Response{}, nil
+}
+
+const registryYAML = `packages:
+  - type: github_release
+    repo_owner: suzuki-shunsuke
+    repo_name: tfcmt
+    aliases:
+      - name: tfcmt
+    asset: tfcmt_{{.OS}}_{{.Arch}}.tar.gz
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: semver("<= 1.0.0")
+      - version_constraint: "true"
+        asset: tfcmt.tar.gz
+`
+
+func TestRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", registryYAML)
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/pkg.yaml", `packages:
+  - name: suzuki-shunsuke/tfcmt@v0.1.0
+  - name: tfcmt
+    version: v1.0.0
+  - name: suzuki-shunsuke/tfcmt
+    version: v0.1.0
+  - name: cli/cli
+    version: v0.2.0
+  - name: suzuki-shunsuke/tfcmt
+`)
+	stdout := &bytes.Buffer{}
+	if err := pkgyaml.Run(t.Context(), slog.New(slog.DiscardHandler), &pkgyaml.Config{
+		Stdout: stdout,
+		Versions: &fakeVersionLister{
+			releases: []string{"v1.0.0", "v0.1.0"},
+		},
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	exp := `pkgs/suzuki-shunsuke/tfcmt/pkg.yaml:5: suzuki-shunsuke/tfcmt@v0.1.0 is duplicated
+pkgs/suzuki-shunsuke/tfcmt/pkg.yaml:7: cli/cli isn't the package name suzuki-shunsuke/tfcmt or its aliases
+pkgs/suzuki-shunsuke/tfcmt/pkg.yaml:7: the version v0.2.0 isn't found in GitHub Releases
+pkgs/suzuki-shunsuke/tfcmt/pkg.yaml:9: the version of suzuki-shunsuke/tfcmt isn't specified
+pkgs/suzuki-shunsuke/tfcmt/pkg.yaml:3: the latest version v1.0.0 isn't selected by the last version_overrides entry, so the entry isn't tested
+`
+	if diff := cmp.Diff(exp, stdout.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRun_fix(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/registry.yaml", registryYAML)
+	testutil.WriteFile(t, "pkgs/suzuki-shunsuke/tfcmt/pkg.yaml", `packages:
+  - name: suzuki-shunsuke/tfcmt@v0.1.0
+  - name: tfcmt
+    version: v0.2.0
+  - name: suzuki-shunsuke/tfcmt
+    version: v0.1.0
+`)
+	stdout := &bytes.Buffer{}
+	if err := pkgyaml.Run(t.Context(), slog.New(slog.DiscardHandler), &pkgyaml.Config{
+		Args:   []string{"suzuki-shunsuke/tfcmt"},
+		Fix:    true,
+		Stdout: stdout,
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	b, err := os.ReadFile("pkgs/suzuki-shunsuke/tfcmt/pkg.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `packages:
+  - name: tfcmt@v0.2.0
+  - name: suzuki-shunsuke/tfcmt
+    version: v0.1.0
+`
+	if diff := cmp.Diff(exp, string(b)); diff != "" {
+		t.Fatal(diff)
+	}
+	exp = "pkgs/suzuki-shunsuke/tfcmt/pkg.yaml:2: the latest version v0.2.0 isn't selected by the last version_overrides entry, so the entry isn't tested\n"
+	if diff := cmp.Diff(exp, stdout.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRun_tags(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    version_source: github_tag
+`)
+	testutil.WriteFile(t, "pkgs/cli/cli/pkg.yaml", `packages:
+  - name: cli/cli@v2.0.0
+`)
+	stdout := &bytes.Buffer{}
+	if err := pkgyaml.Run(t.Context(), slog.New(slog.DiscardHandler), &pkgyaml.Config{
+		Stdout: stdout,
+		Versions: &fakeVersionLister{
+			tags: []string{"v2.0.0"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("no problem should be found: %s", stdout.String())
+	}
+}
@@ -0,0 +1,105 @@
+package pkgyaml
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aquaproj/registry-tool/pkg/pkgfile"
+)
+
+var errProblems = errors.New("pkg.yaml has problems")
+
+// Config is the configuration of Run.
+type Config struct {
+	// Args are package names. If it's empty, all packages having pkg.yaml are checked.
+	Args []string
+	// Fix normalizes and sorts pkg.yaml before checking it.
+	Fix bool
+	// Stdout is the writer problems are output to.
+	Stdout io.Writer
+	// Versions lists versions of packages. If it isn't nil, versions in pkg.yaml must exist.
+	Versions VersionLister
+}
+
+// Run checks pkg.yaml of packages and outputs problems.
+// It returns an error if any problem is found.
+func Run(ctx context.Context, logger *slog.Logger, cfg *Config) error {
+	pkgNames := cfg.Args
+	if len(pkgNames) == 0 {
+		names, err := findPackages()
+		if err != nil {
+			return err
+		}
+		pkgNames = names
+	}
+	num := 0
+	for _, pkgName := range pkgNames {
+		pkg, err := Load(pkgName)
+		if err != nil {
+			return err
+		}
+		if cfg.Fix {
+			pkg, err = fix(logger, pkg)
+			if err != nil {
+				return err
+			}
+		}
+		var versions []string
+		if cfg.Versions != nil {
+			versions, err = listVersions(ctx, cfg.Versions, pkg.Info)
+			if err != nil {
+				return err
+			}
+		}
+		for _, problem := range pkg.Check(versions) {
+			num++
+			fmt.Fprintln(cfg.Stdout, problem.String())
+		}
+	}
+	if num > 0 {
+		return errProblems
+	}
+	return nil
+}
+
+// fix writes the normalized pkg.yaml and returns the package reloaded from it.
+func fix(logger *slog.Logger, pkg *Package) (*Package, error) {
+	b, err := os.ReadFile(pkg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", pkg.Path, err)
+	}
+	formatted := pkg.Format()
+	if bytes.Equal(b, formatted) {
+		return pkg, nil
+	}
+	if err := pkgfile.Write(pkg.Path, formatted); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	logger.Info("fixed pkg.yaml", "file", pkg.Path)
+	return Load(pkg.Name)
+}
+
+// findPackages returns names of packages having pkg.yaml.
+func findPackages() ([]string, error) {
+	paths, err := pkgfile.Find("pkg.yaml")
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	var names []string
+	for _, p := range paths {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(p), pkgfile.RegistryFile)); err != nil {
+			continue
+		}
+		names = append(names, strings.TrimPrefix(filepath.ToSlash(filepath.Dir(p)), "pkgs/"))
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -0,0 +1,66 @@
+package pkgyaml
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/github"
+)
+
+// VersionLister lists GitHub Releases and tags.
+type VersionLister interface {
+	ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+	ListTags(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error)
+}
+
+func versionSourceName(pkg *registry.PackageInfo) string {
+	if pkg.VersionSource == "github_tag" {
+		return "GitHub tags"
+	}
+	return "GitHub Releases"
+}
+
+// listVersions returns versions of the package.
+// If version_source is github_tag, tags are returned. Otherwise, tags of releases are returned.
+// It returns nil if the package isn't hosted on GitHub.
+func listVersions(ctx context.Context, gh VersionLister, pkg *registry.PackageInfo) ([]string, error) {
+	if pkg.RepoOwner == "" || pkg.RepoName == "" {
+		return nil, nil
+	}
+	opts := &github.ListOptions{
+		PerPage: 100, //nolint:mnd
+	}
+	versions := []string{}
+	for range 10 {
+		var resp *github.Response
+		if pkg.VersionSource == "github_tag" {
+			tags, r, err := gh.ListTags(ctx, pkg.RepoOwner, pkg.RepoName, opts)
+			if err != nil {
+				return nil, fmt.Errorf("list tags of %s/%s: %w", pkg.RepoOwner, pkg.RepoName, err)
+			}
+			for _, tag := range tags {
+				versions = append(versions, tag.GetName())
+			}
+			resp = r
+		} else {
+			releases, r, err := gh.ListReleases(ctx, pkg.RepoOwner, pkg.RepoName, opts)
+			if err != nil {
+				return nil, fmt.Errorf("list releases of %s/%s: %w", pkg.RepoOwner, pkg.RepoName, err)
+			}
+			for _, release := range releases {
+				if release.GetDraft() {
+					continue
+				}
+				versions = append(versions, release.GetTagName())
+			}
+			resp = r
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return versions, nil
+		}
+		opts.Page = resp.NextPage
+	}
+	// Old versions may not be listed, so versions aren't checked.
+	return nil, nil
+}
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 
 	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
+	"github.com/aquaproj/registry-tool/pkg/git"
 	"github.com/aquaproj/registry-tool/pkg/osexec"
 )
 
@@ -18,9 +19,11 @@ func ResolveConflict(ctx context.Context, logger *slog.Logger, prNumber string)
 		return errors.New("PR number is required")
 	}
 
+	repo := git.New(logger, "")
+
 	// Fetch origin main
-	if err := run(ctx, logger, "git", "fetch", "origin", "main"); err != nil {
-		return err
+	if err := repo.Fetch(ctx, "origin", "main"); err != nil {
+		return fmt.Errorf("fetch origin main: %w", err)
 	}
 
 	// Checkout the PR
@@ -28,31 +31,30 @@ func ResolveConflict(ctx context.Context, logger *slog.Logger, prNumber string)
 		return err
 	}
 
+	genOpts, err := genrg.OptionsFromConfig()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	// Merge main with registry.yaml backup/restore
-	if err := mergeMainWithBackup(ctx, logger); err != nil {
+	if err := mergeMainWithBackup(ctx, repo, genOpts); err != nil {
 		return err
 	}
 
-	// Stage registry.yaml
-	if err := run(ctx, logger, "git", "add", "registry.yaml"); err != nil {
-		return err
+	// Stage registry.yaml and other generated files
+	if err := repo.Add(ctx, genOpts.Paths()...); err != nil {
+		return fmt.Errorf("stage registry.yaml: %w", err)
 	}
 
 	// Interactive commit
-	commitCmd := exec.CommandContext(ctx, "git", "commit")
-	commitCmd.Stdout = os.Stdout
-	commitCmd.Stderr = os.Stderr
-	commitCmd.Stdin = os.Stdin
-	osexec.SetCancel(logger, commitCmd)
-	logger.Info("+ " + commitCmd.String())
-	if err := commitCmd.Run(); err != nil {
-		return fmt.Errorf("git commit: %w", err)
+	if err := repo.Commit(ctx, &git.CommitOptions{}); err != nil {
+		return fmt.Errorf("commit changes: %w", err)
 	}
 
 	return nil
 }
 
-func mergeMainWithBackup(ctx context.Context, logger *slog.Logger) error {
+func mergeMainWithBackup(ctx context.Context, repo git.Repo, genOpts *genrg.Options) error {
 	// Copy registry.yaml to a temp file
 	tmpFile, err := os.CreateTemp("", "registry-*.yaml")
 	if err != nil {
@@ -65,12 +67,7 @@ func mergeMainWithBackup(ctx context.Context, logger *slog.Logger) error {
 	}
 
 	// Merge origin/main — conflict is expected, so ignore the error
-	cmd := exec.CommandContext(ctx, "git", "merge", "origin/main")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	logger.Info("+ " + cmd.String())
-	_ = cmd.Run()
+	_ = repo.Merge(ctx, "origin/main")
 
 	// Restore registry.yaml from temp file
 	if err := copyFile(tmpFile.Name(), "registry.yaml"); err != nil {
@@ -78,7 +75,7 @@ func mergeMainWithBackup(ctx context.Context, logger *slog.Logger) error {
 	}
 
 	// Regenerate registry.yaml
-	if err := genrg.GenerateRegistry(ctx); err != nil {
+	if err := genrg.GenerateRegistry(ctx, genOpts); err != nil {
 		return fmt.Errorf("generate registry: %w", err)
 	}
 
@@ -12,10 +12,13 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/aquaproj/registry-tool/pkg/config"
 	"github.com/aquaproj/registry-tool/pkg/docker"
 	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
+	"github.com/aquaproj/registry-tool/pkg/git"
 	"github.com/aquaproj/registry-tool/pkg/github"
 	"github.com/aquaproj/registry-tool/pkg/libc"
+	"github.com/suzuki-shunsuke/slog-error/slogerr"
 )
 
 // Scaffold is the main entry point for the scaffold command.
@@ -29,11 +32,31 @@ e.g. $ argd scaffold cli/cli`)
 	// Strip https://github.com/ prefix if present
 	cfg.PkgName = strings.TrimPrefix(cfg.PkgName, "https://github.com/")
 
-	githubToken, err := github.GetAccessToken(ctx, logger)
+	if cfg.Git == nil || cfg.GitHub == nil {
+		argdCfg, err := config.Read()
+		if err != nil {
+			return fmt.Errorf("read the configuration file: %w", err)
+		}
+		if cfg.Git == nil {
+			cfg.Git = argdCfg.Git
+		}
+		if cfg.GitHub == nil {
+			cfg.GitHub = argdCfg.GitHub
+		}
+	}
+
+	if cfg.Repo == nil {
+		cfg.Repo = git.New(logger, "")
+	}
+
+	githubToken, err := github.PrepareAccessToken(ctx, logger, cfg.GitHub, cfg.RequireToken)
 	if err != nil {
 		return fmt.Errorf("get github access token: %w", err)
 	}
 
+	if cfg.Update {
+		return scaffoldUpdate(ctx, logger, cfg, githubToken)
+	}
 	return scaffoldFull(ctx, logger, cfg, githubToken)
 }
 
@@ -45,13 +68,13 @@ func scaffoldFull(ctx context.Context, logger *slog.Logger, cfg *Config, githubT
 		return fmt.Errorf("prerequisites check failed: %w", err)
 	}
 
-	if err := CheckPkgsDiff(ctx, logger); err != nil {
+	if err := CheckPkgsDiff(ctx, cfg.Repo); err != nil {
 		return fmt.Errorf("pkgs directory check failed: %w", err)
 	}
 
 	if !cfg.NoCreateBranch {
 		logger.Info("Setting up git branch")
-		if err := GitCheckout(ctx, logger, pkgName); err != nil {
+		if err := GitCheckout(ctx, cfg.Repo, cfg.Git, pkgName, cfg.Base); err != nil {
 			return fmt.Errorf("git checkout failed: %w", err)
 		}
 	}
@@ -69,12 +92,16 @@ func scaffoldFull(ctx context.Context, logger *slog.Logger, cfg *Config, githubT
 	}
 
 	logger.Info("Updating registry.yaml")
-	if err := genrg.GenerateRegistry(ctx); err != nil {
+	genOpts, err := genrg.OptionsFromConfig()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if err := genrg.GenerateRegistry(ctx, genOpts); err != nil {
 		return fmt.Errorf("update registry.yaml: %w", err)
 	}
 
 	logger.Info("Committing changes")
-	if err := GitCommit(ctx, logger, pkgName); err != nil {
+	if err := GitCommit(ctx, cfg.Repo, cfg.Git, pkgName); err != nil {
 		return fmt.Errorf("git commit failed: %w", err)
 	}
 
@@ -150,8 +177,10 @@ func scaffoldInContainer(ctx context.Context, logger *slog.Logger, dm *docker.Ma
 		return fmt.Errorf("remove existing pkg.yaml in the container if it exists: %w", err)
 	}
 
-	if err := runAquaGRInContainer(ctx, logger, dm, cfg, pkgDir, githubToken); err != nil {
-		return err
+	// errMergeConflict is returned after the other files are copied so that users can resolve conflicts and test the package
+	grErr := runAquaGRInContainer(ctx, logger, dm, cfg, pkgDir, githubToken)
+	if grErr != nil && !errors.Is(grErr, errMergeConflict) {
+		return grErr
 	}
 
 	// Copy results back from container
@@ -169,7 +198,7 @@ func scaffoldInContainer(ctx context.Context, logger *slog.Logger, dm *docker.Ma
 		}
 	}
 
-	return nil
+	return grErr
 }
 
 func copyScaffoldConfig(ctx context.Context, logger *slog.Logger, dm *docker.Manager, cfg *Config, pkgDir string) error {
@@ -191,6 +220,33 @@ func copyScaffoldConfig(ctx context.Context, logger *slog.Logger, dm *docker.Man
 }
 
 func runAquaGRInContainer(ctx context.Context, logger *slog.Logger, dm *docker.Manager, cfg *Config, pkgDir, githubToken string) error {
+	out, err := execAquaGR(ctx, logger, dm, cfg, pkgDir, githubToken)
+	if err != nil {
+		return err
+	}
+	return applyScaffoldOutput(ctx, logger, cfg, filepath.Join(pkgDir, "registry.yaml"), out)
+}
+
+// applyScaffoldOutput writes or merges the output of aqua gr to registry.yaml and records it as the base of the next merge.
+func applyScaffoldOutput(ctx context.Context, logger *slog.Logger, cfg *Config, rgPath string, out []byte) error {
+	var err error
+	if cfg.NoMerge || !fileExists(rgPath) {
+		err = writeRegistryYAML(rgPath, out)
+	} else {
+		err = mergeRegistryYAML(ctx, logger, cfg, rgPath, out)
+	}
+	if err != nil && !errors.Is(err, errMergeConflict) {
+		return err
+	}
+	// Record the output even if conflicts occur so that resolved conflicts don't come back at the next merge
+	if rerr := recordScaffoldOutput(ctx, cfg.Repo, rgPath, append([]byte(schemaComment), out...)); rerr != nil {
+		slogerr.WithError(logger, rerr).Warn("failed to record the output of scaffold")
+	}
+	return err
+}
+
+// execAquaGR runs aqua gr in the container and returns the output.
+func execAquaGR(ctx context.Context, logger *slog.Logger, dm *docker.Manager, cfg *Config, pkgDir, githubToken string) ([]byte, error) {
 	var env map[string]string
 	if githubToken != "" {
 		env = map[string]string{
@@ -214,11 +270,58 @@ func runAquaGRInContainer(ctx context.Context, logger *slog.Logger, dm *docker.M
 	cmd.Stdout = buf
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("docker exec: %w", err)
+		return nil, fmt.Errorf("docker exec: %w", err)
 	}
-	return writeRegistryYAML(filepath.Join(pkgDir, "registry.yaml"), buf.Bytes())
+	return buf.Bytes(), nil
 }
 
+// mergeRegistryYAML merges the output of aqua gr into the existing registry.yaml to keep hand edits.
+// The latest output of scaffold is used as the common base.
+func mergeRegistryYAML(ctx context.Context, logger *slog.Logger, cfg *Config, path string, data []byte) error {
+	ob, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	ours, err := parseFragment(ob)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	theirs, err := parseFragment(append([]byte(schemaComment), data...))
+	if err != nil {
+		return fmt.Errorf("parse the output of aqua gr: %w", err)
+	}
+	var base *fragment
+	bb, err := getScaffoldBase(ctx, logger, cfg.Repo, path)
+	if err != nil {
+		return err
+	}
+	if bb == nil {
+		logger.Warn("the original output of scaffold isn't found in the git history, so registry.yaml is merged without the common base", "path", path)
+	} else {
+		base, err = parseFragment(bb)
+		if err != nil {
+			return fmt.Errorf("parse the original output of scaffold: %w", err)
+		}
+	}
+	resolve := markersResolver
+	if cfg.Interactive {
+		resolve = promptResolver(os.Stdin, os.Stderr)
+	}
+	merged, conflicts, err := mergeFragments(base, ours, theirs, resolve)
+	if err != nil {
+		return fmt.Errorf("merge registry.yaml: %w", err)
+	}
+	if err := os.WriteFile(path, merged, docker.FilePermission); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if conflicts > 0 {
+		return slogerr.With(errMergeConflict, "path", path, "conflicts", conflicts) //nolint:wrapcheck
+	}
+	return nil
+}
+
+const schemaComment = "# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json\n"
+
 func writeRegistryYAML(path string, data []byte) error {
 	f, err := os.Create(path)
 	if err != nil {
@@ -226,7 +329,7 @@ func writeRegistryYAML(path string, data []byte) error {
 	}
 	defer f.Close()
 	w := bufio.NewWriter(f)
-	if _, err := w.WriteString("# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json\n"); err != nil {
+	if _, err := w.WriteString(schemaComment); err != nil {
 		return fmt.Errorf("write yaml-language-server comment to registry.yaml: %w", err)
 	}
 	if _, err := w.Write(data); err != nil {
@@ -1,5 +1,10 @@
 package scaffold
 
+import (
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/git"
+)
+
 // Config holds the configuration for the scaffold command.
 type Config struct {
 	// PkgName is the package name (e.g., "cli/cli")
@@ -12,8 +17,29 @@ type Config struct {
 	Recreate bool
 	// NoCreateBranch skips creating a git branch
 	NoCreateBranch bool
+	// Base is the ref a new branch is created from.
+	// If it's empty, the branch is created from the default branch of the upstream repository.
+	Base string
 	// ConfigPath is the path to scaffold.yaml config file
 	ConfigPath string
+	// NoMerge overwrites the existing registry.yaml instead of merging the output of aqua gr into it
+	NoMerge bool
+	// Update scaffolds only releases newer than the last version_constraint boundary
+	// and appends version_overrides for them
+	Update bool
+	// Interactive asks how to resolve merge conflicts instead of writing conflict markers
+	Interactive bool
+	// RequireToken fails if no GitHub access token is found
+	RequireToken bool
+	// Git is the configuration of branches and commits.
+	// If it's nil, it's read from the configuration file of argd.
+	Git *config.Git
+	// GitHub is the configuration of GitHub.
+	// If it's nil, it's read from the configuration file of argd.
+	GitHub *config.GitHub
+	// Repo is the git repository.
+	// If it's nil, the repository in the current directory is used.
+	Repo git.Repo
 }
 
 const (
@@ -1,136 +1,152 @@
 package scaffold
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
+	"slices"
 	"time"
 
-	"github.com/aquaproj/registry-tool/pkg/osexec"
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/docker"
+	"github.com/aquaproj/registry-tool/pkg/git"
 )
 
 // GitCheckout creates or switches to a feature branch for the package.
-func GitCheckout(ctx context.Context, logger *slog.Logger, pkgName string) error {
-	branch := "feat/" + pkgName
+// If base isn't empty, a new branch is created from base without fetching the upstream repository.
+func GitCheckout(ctx context.Context, repo git.Repo, gitCfg *config.Git, pkgName, base string) error {
+	branch, err := gitCfg.Branch(pkgName)
+	if err != nil {
+		return fmt.Errorf("get a branch name: %w", err)
+	}
 
 	// Check if branch already exists locally
-	if branchExists(ctx, logger, branch) {
-		return gitCheckoutBranch(ctx, logger, branch)
+	exist, err := repo.BranchExists(ctx, branch)
+	if err != nil {
+		return fmt.Errorf("check if the branch exists: %w", err)
+	}
+	if exist {
+		return repo.Checkout(ctx, branch) //nolint:wrapcheck
 	}
 
-	// Create a new branch from upstream main
-	return createBranchFromUpstream(ctx, logger, branch)
-}
+	if base != "" {
+		return repo.CreateBranch(ctx, branch, base) //nolint:wrapcheck
+	}
 
-func branchExists(ctx context.Context, logger *slog.Logger, branch string) bool {
-	cmd := exec.CommandContext(ctx, "git", "show-ref", "--quiet", "refs/heads/"+branch) //nolint:gosec
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-	osexec.SetCancel(logger, cmd)
-	return cmd.Run() == nil
+	// Create a new branch from the default branch of upstream
+	return createBranchFromUpstream(ctx, repo, gitCfg.Upstream, branch)
 }
 
-func gitCheckoutBranch(ctx context.Context, logger *slog.Logger, branch string) error {
-	cmd := exec.CommandContext(ctx, "git", "checkout", branch)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	logger.Info("+ " + cmd.String())
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git checkout %s: %w", branch, err)
+// createBranchFromUpstream creates a branch from the default branch of the upstream repository.
+// If the URL of the upstream repository isn't configured and the remote `upstream` exists, the remote is used.
+// Otherwise a temporary remote is added.
+func createBranchFromUpstream(ctx context.Context, repo git.Repo, upstream *config.Upstream, branch string) error {
+	remote := config.UpstreamRemote
+	remotes, err := repo.Remotes(ctx)
+	if err != nil {
+		return fmt.Errorf("list remotes: %w", err)
 	}
-	return nil
-}
-
-func createBranchFromUpstream(ctx context.Context, logger *slog.Logger, branch string) error {
-	// Create a temporary remote to fetch from upstream
-	tempRemote := "temp-remote-" + time.Now().Format("20060102150405")
-
-	// Add temporary remote
-	cmd := exec.CommandContext(ctx, "git", "remote", "add", tempRemote, "https://github.com/aquaproj/aqua-registry")
-	logger.Info("+ " + cmd.String())
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git remote add: %w", err)
-	}
-
-	// Ensure we remove the temporary remote even if something fails
-	defer func() {
-		rmCmd := exec.CommandContext(ctx, "git", "remote", "remove", tempRemote)
-		logger.Info("+ " + rmCmd.String())
-		rmCmd.Stdout = os.Stdout
-		rmCmd.Stderr = os.Stderr
-		osexec.SetCancel(logger, rmCmd)
-		_ = rmCmd.Run()
-	}()
-
-	// Fetch main from upstream
-	cmd = exec.CommandContext(ctx, "git", "fetch", tempRemote, "main")
-	logger.Info("+ " + cmd.String())
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git fetch: %w", err)
+	if upstream.URL != "" || !slices.Contains(remotes, remote) {
+		url := upstream.URL
+		if url == "" {
+			url = config.DefaultUpstreamURL
+		}
+		// Create a temporary remote to fetch from upstream
+		remote = "temp-remote-" + time.Now().Format("20060102150405")
+
+		if err := repo.AddRemote(ctx, remote, url); err != nil {
+			return fmt.Errorf("add a temporary remote: %w", err)
+		}
+
+		// Ensure we remove the temporary remote even if something fails
+		defer func() {
+			_ = repo.RemoveRemote(ctx, remote)
+		}()
 	}
 
-	// Create and checkout new branch
-	cmd = exec.CommandContext(ctx, "git", "checkout", "-b", branch, tempRemote+"/main")
-	logger.Info("+ " + cmd.String())
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git checkout -b: %w", err)
+	// Fetch the default branch from upstream
+	if err := repo.Fetch(ctx, remote, upstream.Branch); err != nil {
+		return fmt.Errorf("fetch the upstream repository: %w", err)
 	}
 
-	return nil
+	// Create and checkout new branch
+	return repo.CreateBranch(ctx, branch, remote+"/"+upstream.Branch) //nolint:wrapcheck
 }
 
 // GitCommit commits the scaffold changes.
-func GitCommit(ctx context.Context, logger *slog.Logger, pkgName string) error {
+func GitCommit(ctx context.Context, repo git.Repo, gitCfg *config.Git, pkgName string) error {
 	// Stage the registry.yaml and package files
 	pkgDir := filepath.Join("pkgs", filepath.FromSlash(pkgName))
-
-	cmd := exec.CommandContext(ctx, "git", "add", "registry.yaml", pkgDir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git add: %w", err)
+	if err := repo.Add(ctx, "registry.yaml", pkgDir); err != nil {
+		return fmt.Errorf("stage changes: %w", err)
 	}
 
-	// Create commit with conventional commit message
-	commitMsg := fmt.Sprintf("feat(%s): scaffold %s", pkgName, pkgName)
+	commitMsg, err := gitCfg.CommitMessage(pkgName)
+	if err != nil {
+		return fmt.Errorf("get a commit message: %w", err)
+	}
 
-	cmd = exec.CommandContext(ctx, "git", "commit", "-m", commitMsg)
-	logger.Info("+ " + cmd.String())
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git commit: %w", err)
+	if err := repo.Commit(ctx, gitCfg.CommitOptions(commitMsg)); err != nil {
+		return fmt.Errorf("commit changes: %w", err)
 	}
 
 	return nil
 }
 
-// GetCurrentBranch returns the current git branch name.
-func GetCurrentBranch(ctx context.Context, logger *slog.Logger) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("git rev-parse: %w", err)
-	}
-	return strings.TrimSpace(stdout.String()), nil
+// scaffoldOutputPath is the directory in the git directory where the latest outputs of scaffold are recorded.
+const scaffoldOutputPath = "argd/scaffold"
+
+// recordScaffoldOutput records the output of aqua gr as the common base of the next merge.
+// The record is kept in the git directory so that it isn't committed.
+func recordScaffoldOutput(ctx context.Context, repo git.Repo, path string, data []byte) error {
+	p, err := repo.GitPath(ctx, scaffoldOutputPath+"/"+filepath.ToSlash(path))
+	if err != nil {
+		return fmt.Errorf("get the path to record the output of scaffold: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), docker.DirPermission); err != nil {
+		return fmt.Errorf("create a directory to record the output of scaffold: %w", err)
+	}
+	if err := os.WriteFile(p, data, docker.FilePermission); err != nil {
+		return fmt.Errorf("record the output of scaffold: %w", err)
+	}
+	return nil
+}
+
+// getScaffoldBase returns the latest output of scaffold.
+// It's the common base of the current file and a new output of aqua gr.
+// If the output isn't recorded, the file at the commit adding it is used because scaffold commits the output of aqua gr as is.
+// It returns nil if neither is found.
+func getScaffoldBase(ctx context.Context, logger *slog.Logger, repo git.Repo, path string) ([]byte, error) {
+	path = filepath.ToSlash(path)
+	p, err := repo.GitPath(ctx, scaffoldOutputPath+"/"+path)
+	if err != nil {
+		return nil, fmt.Errorf("get the path of the recorded output of scaffold: %w", err)
+	}
+	b, err := os.ReadFile(p)
+	if err == nil {
+		logger.Debug("read the latest output of scaffold", "record", p, "path", path)
+		return b, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read the recorded output of scaffold: %w", err)
+	}
+	commits, err := repo.LogAdded(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("get the commit adding the file: %w", err)
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	// git log lists commits from newest to oldest
+	commit := commits[len(commits)-1]
+	logger.Debug("read the original output of scaffold", "commit", commit, "path", path)
+	b, err = repo.Show(ctx, commit, path)
+	if err != nil {
+		return nil, fmt.Errorf("get the file at the commit: %w", err)
+	}
+	return b, nil
 }
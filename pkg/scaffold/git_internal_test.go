@@ -0,0 +1,149 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/config"
+	"github.com/aquaproj/registry-tool/pkg/git"
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+)
+
+// setupUpstream creates a bare repository whose branch `develop` has a commit, and a clone of it.
+func setupUpstream(t *testing.T) (string, string) {
+	t.Helper()
+	testutil.SetGitEnv(t)
+	root := t.TempDir()
+	bare := filepath.Join(root, "upstream.git")
+	work := filepath.Join(root, "work")
+	testutil.Git(t, root, "init", "--bare", "-b", "develop", bare)
+	testutil.Git(t, root, "clone", bare, work)
+	testutil.Git(t, work, "commit", "--allow-empty", "-m", "init")
+	testutil.Git(t, work, "push", "origin", "develop")
+	clone := filepath.Join(root, "clone")
+	testutil.Git(t, root, "init", "-b", "develop", clone)
+	return bare, clone
+}
+
+func TestGitCheckout(t *testing.T) { //nolint:funlen
+	logger := slog.New(slog.DiscardHandler)
+	ctx := context.Background()
+
+	t.Run("upstream url", func(t *testing.T) {
+		bare, clone := setupUpstream(t)
+		repo := git.New(logger, clone)
+		gitCfg := &config.Git{
+			BranchTemplate: "feat/{{.PackageName}}",
+			Upstream: &config.Upstream{
+				URL:    bare,
+				Branch: "develop",
+			},
+		}
+		if err := GitCheckout(ctx, repo, gitCfg, "foo/bar", ""); err != nil {
+			t.Fatal(err)
+		}
+		if branch := testutil.Git(t, clone, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feat/foo/bar" {
+			t.Fatalf("wanted feat/foo/bar, got %s", branch)
+		}
+		if remotes := testutil.Git(t, clone, "remote"); remotes != "" {
+			t.Fatalf("temporary remote must be removed: %s", remotes)
+		}
+	})
+
+	t.Run("upstream remote", func(t *testing.T) {
+		bare, clone := setupUpstream(t)
+		repo := git.New(logger, clone)
+		testutil.Git(t, clone, "remote", "add", "upstream", bare)
+		gitCfg := &config.Git{
+			BranchTemplate: "feat/{{.PackageName}}",
+			Upstream: &config.Upstream{
+				Branch: "develop",
+			},
+		}
+		if err := GitCheckout(ctx, repo, gitCfg, "foo/bar", ""); err != nil {
+			t.Fatal(err)
+		}
+		if upstream := testutil.Git(t, clone, "rev-parse", "--abbrev-ref", "feat/foo/bar@{upstream}"); upstream != "upstream/develop" {
+			t.Fatalf("wanted upstream/develop, got %s", upstream)
+		}
+	})
+
+	t.Run("base", func(t *testing.T) {
+		_, clone := setupUpstream(t)
+		repo := git.New(logger, clone)
+		testutil.Git(t, clone, "commit", "--allow-empty", "-m", "local")
+		gitCfg := &config.Git{
+			BranchTemplate: "feat/{{.PackageName}}",
+			Upstream: &config.Upstream{
+				// The fetch must be skipped
+				URL:    filepath.Join(t.TempDir(), "not-found.git"),
+				Branch: "develop",
+			},
+		}
+		if err := GitCheckout(ctx, repo, gitCfg, "foo/bar", "develop"); err != nil {
+			t.Fatal(err)
+		}
+		if branch := testutil.Git(t, clone, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feat/foo/bar" {
+			t.Fatalf("wanted feat/foo/bar, got %s", branch)
+		}
+	})
+}
+
+func TestApplyScaffoldOutput_rescaffold(t *testing.T) {
+	testutil.SetGitEnv(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	testutil.Git(t, dir, "init", "-b", "main")
+	logger := slog.New(slog.DiscardHandler)
+	ctx := context.Background()
+	cfg := &Config{Repo: git.New(logger, "")}
+	rgPath := filepath.Join("pkgs", "foo", "bar", "registry.yaml")
+	if err := os.MkdirAll(filepath.Dir(rgPath), 0o755); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	output := func(description string) []byte {
+		return []byte(`packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: ` + description + `
+    asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+    format: tar.gz
+`)
+	}
+	commit := func(msg string) {
+		t.Helper()
+		testutil.Git(t, dir, "add", "pkgs")
+		testutil.Git(t, dir, "commit", "--allow-empty", "-m", msg)
+	}
+
+	if err := applyScaffoldOutput(ctx, logger, cfg, rgPath, output("bar")); err != nil {
+		t.Fatal(err)
+	}
+	commit("scaffold")
+
+	edited := strings.Replace(testutil.ReadFile(t, rgPath), "description: bar", "description: A great tool", 1)
+	testutil.WriteFile(t, rgPath, edited)
+	commit("edit")
+
+	// The description is changed both in registry.yaml and the output of aqua gr
+	if err := applyScaffoldOutput(ctx, logger, cfg, rgPath, output("bar is a tool")); !errors.Is(err, errMergeConflict) {
+		t.Fatalf("wanted errMergeConflict, got %v", err)
+	}
+	// Resolve the conflict by keeping the hand edit
+	testutil.WriteFile(t, rgPath, edited)
+	commit("re-scaffold")
+
+	// The resolved conflict must not come back
+	if err := applyScaffoldOutput(ctx, logger, cfg, rgPath, output("bar is a tool")); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ReadFile(t, rgPath); got != edited {
+		t.Fatalf("the resolution must be kept:\n%s", got)
+	}
+}
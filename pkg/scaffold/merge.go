@@ -0,0 +1,287 @@
+package scaffold
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	wast "github.com/aquaproj/aqua/v2/pkg/ast"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// errMergeConflict is returned when conflict markers are written to registry.yaml.
+var errMergeConflict = errors.New("registry.yaml has merge conflicts. Please resolve them, and run `argd gr` and `argd test`")
+
+const (
+	conflictMarkerOurs   = "<<<<<<< registry.yaml (current)"
+	conflictMarkerSep    = "======="
+	conflictMarkerTheirs = ">>>>>>> aqua gr"
+)
+
+// fragment is a pkgs/<pkg>/registry.yaml split by the top-level keys of the package.
+// Each segment keeps the lines of the key in the original file including comments preceding the key,
+// so merging keys doesn't lose comments.
+type fragment struct {
+	// preamble is lines before the first key of the package.
+	preamble []string
+	// prefix is the sequence entry prefix of the first key, e.g. "  - ".
+	prefix string
+	// indent is the column of package keys.
+	indent   int
+	keys     []string
+	segments map[string][]string
+	// starts is the index of the first line of each segment.
+	starts map[string]int
+}
+
+type resolution int
+
+const (
+	keepOurs resolution = iota
+	takeTheirs
+	writeMarkers
+)
+
+// conflictResolver decides how a key changed both in the current registry.yaml and the output of aqua gr is merged.
+type conflictResolver func(key string, ours, theirs []string) (resolution, error)
+
+func markersResolver(string, []string, []string) (resolution, error) {
+	return writeMarkers, nil
+}
+
+// promptResolver asks users how to resolve conflicts.
+func promptResolver(in io.Reader, out io.Writer) conflictResolver {
+	reader := bufio.NewReader(in)
+	return func(key string, ours, theirs []string) (resolution, error) {
+		fmt.Fprintf(out, "The key %q was changed both in registry.yaml and the output of aqua gr\n", key)
+		fmt.Fprintln(out, conflictMarkerOurs)
+		fmt.Fprintln(out, strings.Join(ours, "\n"))
+		fmt.Fprintln(out, conflictMarkerSep)
+		fmt.Fprintln(out, strings.Join(theirs, "\n"))
+		fmt.Fprintln(out, conflictMarkerTheirs)
+		for {
+			fmt.Fprint(out, "Keep [c]urrent, use [g]enerated, or write conflict [m]arkers? ")
+			line, err := reader.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "c", "current":
+				return keepOurs, nil
+			case "g", "generated":
+				return takeTheirs, nil
+			case "m", "markers":
+				return writeMarkers, nil
+			}
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return writeMarkers, nil
+				}
+				return 0, fmt.Errorf("read an answer: %w", err)
+			}
+		}
+	}
+}
+
+// parseFragment splits a registry.yaml including only one package by the top-level keys of the package.
+func parseFragment(b []byte) (*fragment, error) {
+	file, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse registry.yaml as YAML: %w", err)
+	}
+	if len(file.Docs) == 0 {
+		return nil, errors.New("registry.yaml is empty")
+	}
+	mv, err := wast.FindMappingValueFromNode(file.Docs[0].Body, "packages")
+	if err != nil {
+		return nil, fmt.Errorf("find a mapping node `packages`: %w", err)
+	}
+	if mv == nil {
+		return nil, errors.New("packages isn't found")
+	}
+	seq, ok := mv.Value.(*ast.SequenceNode)
+	if !ok {
+		return nil, errors.New("packages must be a sequence")
+	}
+	if len(seq.Values) != 1 {
+		return nil, errors.New("packages must include only one package")
+	}
+	mvs, err := wast.NormalizeMappingValueNodes(seq.Values[0])
+	if err != nil {
+		return nil, fmt.Errorf("normalize mapping value nodes: %w", err)
+	}
+	if len(mvs) == 0 {
+		return nil, errors.New("package is empty")
+	}
+
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	frg := &fragment{
+		segments: make(map[string][]string, len(mvs)),
+		starts:   make(map[string]int, len(mvs)),
+	}
+	starts := make([]int, len(mvs))
+	for i, mvn := range mvs {
+		pos := mvn.Key.GetToken().Position
+		start := pos.Line - 1
+		if i == 0 {
+			frg.indent = pos.Column - 1
+		} else {
+			// Comments just above the key belong to the key
+			for start-1 > starts[i-1] && isCommentLine(lines[start-1]) {
+				start--
+			}
+		}
+		starts[i] = start
+		frg.keys = append(frg.keys, mvn.Key.String())
+	}
+	frg.preamble = lines[:starts[0]]
+	for i, key := range frg.keys {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		seg := make([]string, end-starts[i])
+		copy(seg, lines[starts[i]:end])
+		frg.segments[key] = seg
+		frg.starts[key] = starts[i]
+	}
+	first := frg.segments[frg.keys[0]]
+	frg.prefix = first[0][:frg.indent]
+	first[0] = strings.Repeat(" ", frg.indent) + first[0][frg.indent:]
+	return frg, nil
+}
+
+func isCommentLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// normalizeSegment returns a string to compare segments ignoring comments and blank lines.
+func normalizeSegment(seg []string) string {
+	lines := make([]string, 0, len(seg))
+	for _, line := range seg {
+		if strings.TrimSpace(line) == "" || isCommentLine(line) {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func reindent(seg []string, from, to int) []string {
+	if seg == nil || from == to {
+		return seg
+	}
+	ret := make([]string, len(seg))
+	for i, line := range seg {
+		if to > from {
+			ret[i] = strings.Repeat(" ", to-from) + line
+			continue
+		}
+		ret[i] = strings.TrimPrefix(line, strings.Repeat(" ", from-to))
+	}
+	return ret
+}
+
+// mergeKeys returns keys of ours, inserting keys only in theirs after the preceding key in theirs.
+func mergeKeys(ours, theirs []string) []string {
+	keys := make([]string, len(ours))
+	copy(keys, ours)
+	for i, key := range theirs {
+		if indexOf(keys, key) != -1 {
+			continue
+		}
+		idx := len(keys)
+		if i > 0 {
+			if j := indexOf(keys, theirs[i-1]); j != -1 {
+				idx = j + 1
+			}
+		}
+		keys = append(keys[:idx], append([]string{key}, keys[idx:]...)...)
+	}
+	return keys
+}
+
+func indexOf(arr []string, s string) int {
+	for i, a := range arr {
+		if a == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeFragments merges theirs (the new output of aqua gr) into ours (the current registry.yaml).
+// base is the common base of them. base may be nil.
+// It returns the merged registry.yaml and the number of conflicts written as conflict markers.
+func mergeFragments(base, ours, theirs *fragment, resolve conflictResolver) ([]byte, int, error) {
+	if base == nil {
+		base = &fragment{}
+	}
+	conflicts := 0
+	keys := mergeKeys(ours.keys, theirs.keys)
+	lines := make([]string, 0, len(ours.preamble))
+	lines = append(lines, ours.preamble...)
+	written := false
+	for _, key := range keys {
+		oSeg := ours.segments[key]
+		tSeg := reindent(theirs.segments[key], theirs.indent, ours.indent)
+		bSeg := reindent(base.segments[key], base.indent, ours.indent)
+		o, t, b := normalizeSegment(oSeg), normalizeSegment(tSeg), normalizeSegment(bSeg)
+		var seg []string
+		switch {
+		case o == t, b == t:
+			seg = oSeg
+		case b == o:
+			seg = tSeg
+		default:
+			res, err := resolve(key, oSeg, tSeg)
+			if err != nil {
+				return nil, 0, err
+			}
+			switch res {
+			case keepOurs:
+				seg = oSeg
+			case takeTheirs:
+				seg = tSeg
+			case writeMarkers:
+				conflicts++
+				if !written {
+					oSeg = withPrefix(oSeg, ours.prefix, ours.indent)
+					tSeg = withPrefix(tSeg, ours.prefix, ours.indent)
+					written = true
+				}
+				lines = append(lines, conflictMarkerOurs)
+				lines = append(lines, oSeg...)
+				lines = append(lines, conflictMarkerSep)
+				lines = append(lines, tSeg...)
+				lines = append(lines, conflictMarkerTheirs)
+				continue
+			}
+		}
+		if len(seg) == 0 {
+			continue
+		}
+		if !written {
+			seg = withPrefix(seg, ours.prefix, ours.indent)
+			written = true
+		}
+		lines = append(lines, seg...)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), conflicts, nil
+}
+
+// withPrefix prepends the sequence entry prefix to the key line of the first key of the package.
+func withPrefix(seg []string, prefix string, indent int) []string {
+	ret := make([]string, len(seg))
+	copy(ret, seg)
+	for i, line := range ret {
+		if isCommentLine(line) || strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(line) >= indent {
+			ret[i] = prefix + line[indent:]
+		}
+		break
+	}
+	return ret
+}
@@ -0,0 +1,181 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const mergeBase = `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+    format: tar.gz
+`
+
+func TestMergeFragments(t *testing.T) { //nolint:funlen
+	t.Parallel()
+	data := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		exp       string
+		conflicts int
+	}{
+		{
+			name: "keep hand edits and take new fields",
+			base: mergeBase,
+			ours: `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    # curated description
+    description: A great tool
+    asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+    format: tar.gz
+    files:
+      - name: bar
+        src: bin/bar
+`,
+			theirs: `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+    format: tar.gz
+    checksum:
+      type: github_release
+      asset: checksums.txt
+      algorithm: sha256
+`,
+			exp: `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    # curated description
+    description: A great tool
+    asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+    format: tar.gz
+    checksum:
+      type: github_release
+      asset: checksums.txt
+      algorithm: sha256
+    files:
+      - name: bar
+        src: bin/bar
+`,
+		},
+		{
+			name: "conflict",
+			base: mergeBase,
+			ours: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    asset: bar-{{.OS}}-{{.Arch}}.tar.gz
+    format: tar.gz
+`,
+			theirs: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    asset: bar_{{.OS}}_{{.Arch}}.zip
+    format: zip
+`,
+			exp: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+<<<<<<< registry.yaml (current)
+    asset: bar-{{.OS}}-{{.Arch}}.tar.gz
+=======
+    asset: bar_{{.OS}}_{{.Arch}}.zip
+>>>>>>> aqua gr
+    format: zip
+`,
+			conflicts: 1,
+		},
+		{
+			name: "no base",
+			ours: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: A great tool
+`,
+			theirs: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+`,
+			exp: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+<<<<<<< registry.yaml (current)
+    description: A great tool
+=======
+    description: bar
+>>>>>>> aqua gr
+`,
+			conflicts: 1,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			var base *fragment
+			if d.base != "" {
+				b, err := parseFragment([]byte(d.base))
+				if err != nil {
+					t.Fatal(err)
+				}
+				base = b
+			}
+			ours, err := parseFragment([]byte(d.ours))
+			if err != nil {
+				t.Fatal(err)
+			}
+			theirs, err := parseFragment([]byte(d.theirs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			merged, conflicts, err := mergeFragments(base, ours, theirs, markersResolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(d.exp, string(merged)); diff != "" {
+				t.Fatalf("merged registry.yaml (-want +got):\n%s", diff)
+			}
+			if conflicts != d.conflicts {
+				t.Fatalf("wanted %d conflicts, got %d", d.conflicts, conflicts)
+			}
+		})
+	}
+}
+
+func TestPromptResolver(t *testing.T) {
+	t.Parallel()
+	out := &strings.Builder{}
+	resolve := promptResolver(strings.NewReader("x\ng\n"), out)
+	res, err := resolve("asset", []string{"    asset: a"}, []string{"    asset: b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != takeTheirs {
+		t.Fatalf("wanted takeTheirs, got %d", res)
+	}
+}
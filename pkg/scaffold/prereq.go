@@ -1,15 +1,14 @@
 package scaffold
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/aquaproj/registry-tool/pkg/git"
 	"github.com/aquaproj/registry-tool/pkg/osexec"
 )
 
@@ -37,57 +36,19 @@ func checkCommand(ctx context.Context, logger *slog.Logger, name string) error {
 }
 
 // CheckPkgsDiff checks if the pkgs directory has uncommitted changes.
-func CheckPkgsDiff(ctx context.Context, logger *slog.Logger) error {
-	// Check for unstaged changes
-	if err := gitDiffQuiet(ctx, logger, "pkgs"); err != nil {
+func CheckPkgsDiff(ctx context.Context, repo git.Repo) error {
+	st, err := repo.Status(ctx, "pkgs")
+	if err != nil {
+		return fmt.Errorf("get the status of pkgs: %w", err)
+	}
+	if len(st.Unstaged) > 0 {
 		return errors.New("the directory pkgs has unstaged changes")
 	}
-
-	// Check for staged changes
-	if err := gitDiffCachedQuiet(ctx, logger, "pkgs"); err != nil {
+	if len(st.Staged) > 0 {
 		return errors.New("the directory pkgs has staged changes")
 	}
-
-	// Check for untracked files
-	untracked, err := gitLsFilesOthers(ctx, logger, "pkgs")
-	if err != nil {
-		return fmt.Errorf("check untracked files: %w", err)
-	}
-	if len(untracked) > 0 {
-		return fmt.Errorf("the directory pkgs has untracked files:\n%s", strings.Join(untracked, "\n"))
+	if len(st.Untracked) > 0 {
+		return fmt.Errorf("the directory pkgs has untracked files:\n%s", strings.Join(st.Untracked, "\n"))
 	}
-
 	return nil
 }
-
-func gitDiffQuiet(ctx context.Context, logger *slog.Logger, path string) error {
-	cmd := exec.CommandContext(ctx, "git", "diff", "--quiet", path)
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-	osexec.SetCancel(logger, cmd)
-	return cmd.Run() //nolint:wrapcheck
-}
-
-func gitDiffCachedQuiet(ctx context.Context, logger *slog.Logger, path string) error {
-	cmd := exec.CommandContext(ctx, "git", "diff", "--cached", "--quiet", path)
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-	osexec.SetCancel(logger, cmd)
-	return cmd.Run() //nolint:wrapcheck
-}
-
-func gitLsFilesOthers(ctx context.Context, logger *slog.Logger, path string) ([]string, error) {
-	cmd := exec.CommandContext(ctx, "git", "ls-files", "--others", "--exclude-standard", path)
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = os.Stderr
-	osexec.SetCancel(logger, cmd)
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("git ls-files: %w", err)
-	}
-	output := strings.TrimSpace(stdout.String())
-	if output == "" {
-		return nil, nil
-	}
-	return strings.Split(output, "\n"), nil
-}
@@ -0,0 +1,503 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	wast "github.com/aquaproj/aqua/v2/pkg/ast"
+	"github.com/aquaproj/aqua/v2/pkg/config/registry"
+	"github.com/aquaproj/registry-tool/pkg/docker"
+	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
+	"github.com/aquaproj/registry-tool/pkg/github"
+	"github.com/aquaproj/registry-tool/pkg/semver"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+const keyVersionOverrides = "version_overrides"
+
+// releaseLister lists GitHub Releases.
+type releaseLister interface {
+	ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+}
+
+// scaffoldUpdate scaffolds only releases newer than the last version_constraint boundary of the package,
+// and appends version_overrides for them so that existing version_overrides are kept as they are.
+func scaffoldUpdate(ctx context.Context, logger *slog.Logger, cfg *Config, githubToken string) error { //nolint:cyclop,funlen
+	pkgName := cfg.PkgName
+	pkgDir := filepath.Join(append([]string{"pkgs"}, strings.Split(pkgName, "/")...)...)
+	rgPath := filepath.Join(pkgDir, "registry.yaml")
+	pkgYAMLPath := filepath.Join(pkgDir, "pkg.yaml")
+
+	if err := CheckPrerequisites(ctx, logger); err != nil {
+		return fmt.Errorf("prerequisites check failed: %w", err)
+	}
+
+	if err := CheckPkgsDiff(ctx, cfg.Repo); err != nil {
+		return fmt.Errorf("pkgs directory check failed: %w", err)
+	}
+
+	rb, err := os.ReadFile(rgPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", rgPath, err)
+	}
+	pb, err := os.ReadFile(pkgYAMLPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", pkgYAMLPath, err)
+	}
+	pkgInfo, err := readPackageInfo(rb)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", rgPath, err)
+	}
+	if len(pkgInfo.VersionOverrides) == 0 {
+		return errors.New("the package has no version_overrides. Please scaffold it without --update")
+	}
+	latest, err := latestVersionInPkgYAML(pb)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", pkgYAMLPath, err)
+	}
+	boundary := versionBoundary(pkgInfo, latest)
+	if boundary == "" {
+		return errors.New("the last version_constraint boundary isn't found")
+	}
+
+	gh, err := github.New(ctx, logger, cfg.GitHub)
+	if err != nil {
+		return fmt.Errorf("create a GitHub client: %w", err)
+	}
+	defer gh.LogStats()
+	num, err := countNewReleases(ctx, gh, pkgInfo.RepoOwner, pkgInfo.RepoName, boundary)
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		logger.Info("no release is newer than the last version_constraint boundary", "boundary", boundary)
+		return nil
+	}
+	logger.Info("scaffolding new releases", "boundary", boundary, "num_of_releases", num)
+
+	if !cfg.NoCreateBranch {
+		logger.Info("Setting up git branch")
+		if err := GitCheckout(ctx, cfg.Repo, cfg.Git, pkgName, cfg.Base); err != nil {
+			return fmt.Errorf("git checkout failed: %w", err)
+		}
+	}
+
+	logger.Info("Starting Linux container")
+	linuxDM := docker.NewManager(docker.DefaultLinuxContainer())
+	if err := linuxDM.EnsureContainer(ctx, logger, cfg.Recreate); err != nil {
+		return fmt.Errorf("failed to ensure Linux container: %w", err)
+	}
+
+	logger.Info("Running scaffold in container")
+	if err := copyScaffoldConfig(ctx, logger, linuxDM, cfg, pkgDir); err != nil {
+		return err
+	}
+	if err := linuxDM.ExecBash(ctx, logger, "rm pkg.yaml 2>/dev/null || :"); err != nil {
+		return fmt.Errorf("remove existing pkg.yaml in the container if it exists: %w", err)
+	}
+	grCfg := *cfg
+	grCfg.Limit = num
+	generated, err := execAquaGR(ctx, logger, linuxDM, &grCfg, pkgDir, githubToken)
+	if err != nil {
+		return err
+	}
+
+	updated, changed, err := appendVersionOverrides(rb, append([]byte(schemaComment), generated...), latest)
+	if err != nil {
+		return fmt.Errorf("append version_overrides: %w", err)
+	}
+	if changed {
+		if err := os.WriteFile(rgPath, updated, docker.FilePermission); err != nil {
+			return fmt.Errorf("write %s: %w", rgPath, err)
+		}
+	} else {
+		logger.Info("version_overrides don't need to be updated")
+	}
+
+	tmpPkgYAML, err := os.CreateTemp("", "pkg-*.yaml")
+	if err != nil {
+		return fmt.Errorf("create a temporary file: %w", err)
+	}
+	tmpPkgYAML.Close()
+	defer os.Remove(tmpPkgYAML.Name())
+	if err := linuxDM.CopyFrom(ctx, logger, linuxDM.Config().WorkingDir+"/pkg.yaml", tmpPkgYAML.Name()); err != nil {
+		return fmt.Errorf("copy pkg.yaml from container: %w", err)
+	}
+	gb, err := os.ReadFile(tmpPkgYAML.Name())
+	if err != nil {
+		return fmt.Errorf("read pkg.yaml generated by aqua gr: %w", err)
+	}
+	if err := os.WriteFile(pkgYAMLPath, mergePkgYAML(pb, gb), docker.FilePermission); err != nil {
+		return fmt.Errorf("write %s: %w", pkgYAMLPath, err)
+	}
+
+	logger.Info("Updating registry.yaml")
+	genOpts, err := genrg.OptionsFromConfig()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if err := genrg.GenerateRegistry(ctx, genOpts); err != nil {
+		return fmt.Errorf("update registry.yaml: %w", err)
+	}
+
+	logger.Info("Committing changes")
+	if err := GitCommit(ctx, cfg.Repo, cfg.Git, pkgName); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+
+	return runTests(ctx, logger, cfg, linuxDM, pkgName, githubToken)
+}
+
+func readPackageInfo(b []byte) (*registry.PackageInfo, error) {
+	cfg := &registry.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parse registry.yaml as YAML: %w", err)
+	}
+	if len(cfg.PackageInfos) != 1 {
+		return nil, errors.New("packages must include only one package")
+	}
+	return cfg.PackageInfos[0], nil
+}
+
+type pkgYAML struct {
+	Packages []struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"packages"`
+}
+
+// latestVersionInPkgYAML returns the latest version in pkg.yaml.
+// It returns an empty string if pkg.yaml has no version.
+func latestVersionInPkgYAML(b []byte) (string, error) {
+	pkgs := &pkgYAML{}
+	if err := yaml.Unmarshal(b, pkgs); err != nil {
+		return "", fmt.Errorf("parse pkg.yaml as YAML: %w", err)
+	}
+	latest := ""
+	for _, pkg := range pkgs.Packages {
+		v := pkg.Version
+		if _, version, ok := strings.Cut(pkg.Name, "@"); ok {
+			v = version
+		}
+		if v == "" {
+			continue
+		}
+		if latest == "" || semver.GreaterThan(v, latest) {
+			latest = v
+		}
+	}
+	return latest, nil
+}
+
+var boundaryPatterns = []*regexp.Regexp{ //nolint:gochecknoglobals
+	regexp.MustCompile(`semver\("<=\s*([^"]+)"\)`),
+	regexp.MustCompile(`Version == "([^"]+)"`),
+}
+
+// versionBoundary returns the latest version covered by version_overrides.
+// aqua gr sets `version_constraint: "true"` to the last version_overrides entry,
+// so the latest version in pkg.yaml is also taken into account.
+func versionBoundary(pkgInfo *registry.PackageInfo, latest string) string {
+	boundary := latest
+	for _, vo := range pkgInfo.VersionOverrides {
+		for _, pattern := range boundaryPatterns {
+			for _, m := range pattern.FindAllStringSubmatch(vo.VersionConstraints, -1) {
+				if boundary == "" || semver.GreaterThan(m[1], boundary) {
+					boundary = m[1]
+				}
+			}
+		}
+	}
+	return boundary
+}
+
+// countNewReleases returns the number of releases newer than boundary.
+func countNewReleases(ctx context.Context, gh releaseLister, owner, repo, boundary string) (int, error) {
+	opts := &github.ListOptions{
+		PerPage: 100, //nolint:mnd
+	}
+	num := 0
+	for range 10 {
+		releases, resp, err := gh.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return 0, fmt.Errorf("list releases: %w", err)
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue
+			}
+			switch tag := release.GetTagName(); tag {
+			case "latest", "nightly", "stable":
+				continue
+			default:
+				if semver.GreaterThan(tag, boundary) {
+					num++
+				}
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return num, nil
+		}
+		opts.Page = resp.NextPage
+	}
+	return num, nil
+}
+
+// voEntry is an entry of version_overrides.
+type voEntry struct {
+	constraint string
+	// constraintLine is the index of the line of version_constraint.
+	constraintLine int
+	// body is the lines of the entry except for version_constraint.
+	// The dash of the entry is replaced with a space.
+	body []string
+	// indent is the column of the keys of the entry.
+	indent int
+}
+
+func (e *voEntry) render(indent int) []string {
+	lines := []string{strings.Repeat(" ", indent-2) + "- version_constraint: " + formatConstraint(e.constraint)} //nolint:mnd
+	return append(lines, reindent(e.body, e.indent, indent)...)
+}
+
+// formatConstraint formats version_constraint as a YAML scalar.
+func formatConstraint(c string) string {
+	if c == "true" || c == "false" {
+		return fmt.Sprintf("%q", c)
+	}
+	return c
+}
+
+// parseVersionOverrides returns entries of version_overrides and the index of the line following the last entry.
+func parseVersionOverrides(b []byte, lines []string) ([]*voEntry, int, error) {
+	file, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return nil, 0, fmt.Errorf("parse registry.yaml as YAML: %w", err)
+	}
+	frg, err := parseFragment(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	seg, ok := frg.segments[keyVersionOverrides]
+	if !ok {
+		return nil, 0, nil
+	}
+	end := frg.starts[keyVersionOverrides] + len(seg)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	pkgs, err := wast.FindMappingValueFromNode(file.Docs[0].Body, "packages")
+	if err != nil {
+		return nil, 0, fmt.Errorf("find a mapping node `packages`: %w", err)
+	}
+	pkg := pkgs.Value.(*ast.SequenceNode).Values[0] //nolint:forcetypeassert // parseFragment validates it
+	mv, err := wast.FindMappingValueFromNode(pkg, keyVersionOverrides)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find a mapping node `version_overrides`: %w", err)
+	}
+	seq, ok := mv.Value.(*ast.SequenceNode)
+	if !ok {
+		return nil, 0, errors.New("version_overrides must be a sequence")
+	}
+	starts := make([]int, len(seq.Values))
+	for i, value := range seq.Values {
+		mvs, err := wast.NormalizeMappingValueNodes(value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("normalize mapping value nodes: %w", err)
+		}
+		if len(mvs) == 0 {
+			return nil, 0, errors.New("version_overrides entry is empty")
+		}
+		starts[i] = mvs[0].Key.GetToken().Position.Line - 1
+	}
+	entries := make([]*voEntry, len(seq.Values))
+	for i, value := range seq.Values {
+		entryEnd := end
+		if i+1 < len(starts) {
+			entryEnd = starts[i+1]
+		}
+		entry, err := newVOEntry(value, lines, starts[i], entryEnd)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries[i] = entry
+	}
+	return entries, end, nil
+}
+
+func newVOEntry(node ast.Node, lines []string, start, end int) (*voEntry, error) {
+	mvs, err := wast.NormalizeMappingValueNodes(node)
+	if err != nil {
+		return nil, fmt.Errorf("normalize mapping value nodes: %w", err)
+	}
+	entry := &voEntry{
+		constraintLine: -1,
+		indent:         mvs[0].Key.GetToken().Position.Column - 1,
+	}
+	for _, mvn := range mvs {
+		if mvn.Key.String() != "version_constraint" {
+			continue
+		}
+		entry.constraintLine = mvn.Key.GetToken().Position.Line - 1
+		if sn, ok := mvn.Value.(*ast.StringNode); ok {
+			entry.constraint = sn.Value
+		} else {
+			entry.constraint = mvn.Value.String()
+		}
+	}
+	for i := start; i < end; i++ {
+		if i == entry.constraintLine {
+			continue
+		}
+		line := lines[i]
+		if i == start && len(line) >= entry.indent {
+			line = strings.Repeat(" ", entry.indent) + line[entry.indent:]
+		}
+		entry.body = append(entry.body, line)
+	}
+	return entry, nil
+}
+
+// entriesFromGenerated returns version_overrides entries of the output of aqua gr.
+// If the output has no version_overrides, the package itself is converted to an entry matching all versions.
+func entriesFromGenerated(b []byte) ([]*voEntry, error) {
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	entries, _, err := parseVersionOverrides(b, lines)
+	if err != nil {
+		return nil, err
+	}
+	if entries != nil {
+		return entries, nil
+	}
+	frg, err := parseFragment(b)
+	if err != nil {
+		return nil, err
+	}
+	entry := &voEntry{
+		constraint: "true",
+		indent:     frg.indent,
+	}
+	for _, key := range frg.keys {
+		switch key {
+		case "name", "aliases", "type", "repo_owner", "repo_name", "description", "link", "search_words", "version_constraint":
+			continue
+		}
+		entry.body = append(entry.body, frg.segments[key]...)
+	}
+	return []*voEntry{entry}, nil
+}
+
+// appendVersionOverrides appends version_overrides generated by aqua gr for new releases to the current registry.yaml.
+// Existing entries are kept as they are except for version_constraint of the last entry,
+// which is extended if the first new entry has the same configuration,
+// or narrowed to the latest version scaffolded before if it matches all versions.
+// latest is the latest version scaffolded before.
+// It returns the updated registry.yaml and whether it's changed.
+func appendVersionOverrides(current, generated []byte, latest string) ([]byte, bool, error) { //nolint:cyclop
+	lines := strings.Split(strings.TrimRight(string(current), "\n"), "\n")
+	oldEntries, end, err := parseVersionOverrides(current, lines)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(oldEntries) == 0 {
+		return nil, false, errors.New("the package has no version_overrides")
+	}
+	newEntries, err := entriesFromGenerated(generated)
+	if err != nil {
+		return nil, false, fmt.Errorf("read the output of aqua gr: %w", err)
+	}
+	if len(newEntries) == 0 {
+		return current, false, nil
+	}
+	last := oldEntries[len(oldEntries)-1]
+	if last.constraintLine == -1 {
+		return nil, false, errors.New("version_constraint of the last version_overrides entry isn't found")
+	}
+
+	constraint := last.constraint
+	if normalizeSegment(reindent(last.body, last.indent, 0)) == normalizeSegment(reindent(newEntries[0].body, newEntries[0].indent, 0)) {
+		// The last entry covers the first new entry
+		constraint = newEntries[0].constraint
+		newEntries = newEntries[1:]
+		if last.constraint == "true" && len(newEntries) == 0 {
+			return current, false, nil
+		}
+	} else if last.constraint == "true" {
+		v := semver.Parse(latest)
+		if v == nil {
+			return nil, false, fmt.Errorf("the latest version %q isn't semver", latest)
+		}
+		constraint = fmt.Sprintf(`semver("<= %s")`, strings.TrimPrefix(v.String(), "v"))
+	}
+
+	ret := make([]string, 0, len(lines))
+	ret = append(ret, lines[:end]...)
+	if constraint != last.constraint {
+		line := ret[last.constraintLine]
+		idx := strings.Index(line, "version_constraint:")
+		ret[last.constraintLine] = line[:idx] + "version_constraint: " + formatConstraint(constraint)
+	}
+	for _, entry := range newEntries {
+		ret = append(ret, entry.render(last.indent)...)
+	}
+	ret = append(ret, lines[end:]...)
+	return []byte(strings.Join(ret, "\n") + "\n"), true, nil
+}
+
+// mergePkgYAML prepends packages of pkg.yaml generated by aqua gr to the current pkg.yaml.
+// Packages already in the current pkg.yaml are skipped.
+func mergePkgYAML(current, generated []byte) []byte {
+	curHeader, curEntries := splitPkgYAML(current)
+	_, genEntries := splitPkgYAML(generated)
+	existing := make(map[string]struct{}, len(curEntries))
+	for _, entry := range curEntries {
+		existing[normalizeSegment(entry)] = struct{}{}
+	}
+	lines := curHeader
+	for _, entry := range genEntries {
+		if _, ok := existing[normalizeSegment(entry)]; ok {
+			continue
+		}
+		lines = append(lines, entry...)
+	}
+	for _, entry := range curEntries {
+		lines = append(lines, entry...)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// splitPkgYAML splits pkg.yaml into lines until `packages:` and entries of packages.
+func splitPkgYAML(b []byte) ([]string, [][]string) {
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	var header []string
+	var entries [][]string
+	inPackages := false
+	dash := ""
+	for _, line := range lines {
+		if !inPackages {
+			header = append(header, line)
+			inPackages = strings.HasPrefix(line, "packages:")
+			continue
+		}
+		if len(entries) == 0 {
+			dash = line[:len(line)-len(strings.TrimLeft(line, " "))] + "- "
+			entries = append(entries, []string{line})
+			continue
+		}
+		if strings.HasPrefix(line, dash) {
+			entries = append(entries, []string{line})
+			continue
+		}
+		entries[len(entries)-1] = append(entries[len(entries)-1], line)
+	}
+	return header, entries
+}
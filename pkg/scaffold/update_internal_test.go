@@ -0,0 +1,159 @@
+package scaffold
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const updateCurrent = `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: semver("<= 0.1.0")
+        # hand-tuned
+        asset: bar-{{.OS}}.tar.gz
+      - version_constraint: "true"
+        asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+`
+
+func TestAppendVersionOverrides(t *testing.T) { //nolint:funlen
+	t.Parallel()
+	data := []struct {
+		name      string
+		generated string
+		exp       string
+		changed   bool
+	}{
+		{
+			name: "same asset",
+			generated: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+`,
+			exp: updateCurrent,
+		},
+		{
+			name: "asset is changed",
+			generated: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    asset: bar_{{.OS}}_{{.Arch}}.zip
+    format: zip
+`,
+			exp: `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: semver("<= 0.1.0")
+        # hand-tuned
+        asset: bar-{{.OS}}.tar.gz
+      - version_constraint: semver("<= 0.2.0")
+        asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+      - version_constraint: "true"
+        asset: bar_{{.OS}}_{{.Arch}}.zip
+        format: zip
+`,
+			changed: true,
+		},
+		{
+			name: "asset is changed in the middle of new releases",
+			generated: `packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: semver("<= 0.3.0")
+        asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+      - version_constraint: "true"
+        asset: bar_{{.OS}}_{{.Arch}}.zip
+`,
+			exp: `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: foo
+    repo_name: bar
+    description: bar
+    version_constraint: "false"
+    version_overrides:
+      - version_constraint: semver("<= 0.1.0")
+        # hand-tuned
+        asset: bar-{{.OS}}.tar.gz
+      - version_constraint: semver("<= 0.3.0")
+        asset: bar_{{.OS}}_{{.Arch}}.tar.gz
+      - version_constraint: "true"
+        asset: bar_{{.OS}}_{{.Arch}}.zip
+`,
+			changed: true,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			b, changed, err := appendVersionOverrides([]byte(updateCurrent), []byte(d.generated), "v0.2.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != d.changed {
+				t.Fatalf("wanted changed %v, got %v", d.changed, changed)
+			}
+			if diff := cmp.Diff(d.exp, string(b)); diff != "" {
+				t.Fatalf("registry.yaml (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVersionBoundary(t *testing.T) {
+	t.Parallel()
+	pkgInfo, err := readPackageInfo([]byte(updateCurrent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := versionBoundary(pkgInfo, "v0.2.0"); b != "v0.2.0" {
+		t.Fatalf("wanted v0.2.0, got %s", b)
+	}
+	if b := versionBoundary(pkgInfo, ""); b != "0.1.0" {
+		t.Fatalf("wanted 0.1.0, got %s", b)
+	}
+}
+
+func TestMergePkgYAML(t *testing.T) {
+	t.Parallel()
+	current := `packages:
+  - name: foo/bar@v0.2.0
+  - name: foo/bar
+    version: v0.1.0
+`
+	generated := `packages:
+  - name: foo/bar@v0.3.0
+  - name: foo/bar
+    version: v0.2.0
+`
+	exp := `packages:
+  - name: foo/bar@v0.3.0
+  - name: foo/bar
+    version: v0.2.0
+  - name: foo/bar@v0.2.0
+  - name: foo/bar
+    version: v0.1.0
+`
+	if diff := cmp.Diff(exp, string(mergePkgYAML([]byte(current), []byte(generated)))); diff != "" {
+		t.Fatalf("pkg.yaml (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,39 @@
+// Package semver parses release tags as semantic versions in the same way as aqua.
+package semver
+
+import (
+	"regexp"
+
+	"github.com/hashicorp/go-version"
+)
+
+var versionPattern = regexp.MustCompile(`^(.*?)v?((?:\d+)(?:\.\d+)?(?:\.\d+)?(?:(\.|-).+)?)$`)
+
+// Parse parses a tag as a semantic version.
+// Tags may have prefixes such as "cli/v1.2.3".
+// It returns nil if the tag doesn't include a version.
+func Parse(tag string) *version.Version {
+	if v, err := version.NewVersion(tag); err == nil {
+		return v
+	}
+	a := versionPattern.FindStringSubmatch(tag)
+	if a == nil {
+		return nil
+	}
+	v, err := version.NewVersion(a[2])
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// GreaterThan reports whether the tag v1 is greater than the tag v2.
+// Tags which can't be parsed as semantic versions are compared as strings.
+func GreaterThan(v1, v2 string) bool {
+	sv1 := Parse(v1)
+	sv2 := Parse(v2)
+	if sv1 == nil || sv2 == nil {
+		return v1 > v2
+	}
+	return sv1.GreaterThan(sv2)
+}
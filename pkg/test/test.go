@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"path/filepath"
 
+	"github.com/aquaproj/registry-tool/pkg/config"
 	"github.com/aquaproj/registry-tool/pkg/docker"
 	genrg "github.com/aquaproj/registry-tool/pkg/generate-registry"
 	"github.com/aquaproj/registry-tool/pkg/github"
@@ -18,6 +19,8 @@ import (
 type Config struct {
 	PkgName  string
 	Recreate bool
+	// RequireToken fails if no GitHub access token is found
+	RequireToken bool
 }
 
 // Test tests a package in Docker containers across all platforms.
@@ -27,7 +30,12 @@ func Test(ctx context.Context, logger *slog.Logger, cfg *Config) error {
 		return fmt.Errorf("resolve package name: %w", err)
 	}
 
-	githubToken, err := github.GetAccessToken(ctx, logger)
+	argdCfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("read the configuration file: %w", err)
+	}
+
+	githubToken, err := github.PrepareAccessToken(ctx, logger, argdCfg.GitHub, cfg.RequireToken)
 	if err != nil {
 		return fmt.Errorf("get a GitHub access token: %w", err)
 	}
@@ -62,7 +70,11 @@ func Test(ctx context.Context, logger *slog.Logger, cfg *Config) error {
 
 	// Update registry.yaml
 	logger.Info("Updating registry.yaml")
-	if err := genrg.GenerateRegistry(ctx); err != nil {
+	genOpts, err := genrg.OptionsFromConfig()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if err := genrg.GenerateRegistry(ctx, genOpts); err != nil {
 		return fmt.Errorf("update registry.yaml: %w", err)
 	}
 
@@ -0,0 +1,125 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var errInvalid = errors.New("files are invalid")
+
+// Config is the configuration of Run.
+type Config struct {
+	// Args are paths to files or directories.
+	// registry.yaml, pkg.yaml, and scaffold.yaml in directories are validated.
+	// If it's empty, files in the directory pkgs are validated.
+	Args []string
+	// Format is the output format. text or json.
+	Format string
+	// Stdout is the writer errors are output to.
+	Stdout io.Writer
+}
+
+// Run validates files and outputs violations of JSON Schemas.
+// It returns an error if any violation is found.
+func Run(cfg *Config) error {
+	switch cfg.Format {
+	case FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("format must be text or json: %s", cfg.Format)
+	}
+	paths, err := targets(cfg.Args)
+	if err != nil {
+		return err
+	}
+	v, err := New()
+	if err != nil {
+		return err
+	}
+	errs := []*Error{}
+	for _, p := range paths {
+		fileErrs, err := v.ValidateFile(p)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, fileErrs...)
+	}
+	if err := write(cfg.Stdout, cfg.Format, errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errInvalid
+	}
+	return nil
+}
+
+func write(w io.Writer, format string, errs []*Error) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(errs); err != nil {
+			return fmt.Errorf("encode errors as JSON: %w", err)
+		}
+		return nil
+	}
+	for _, e := range errs {
+		if _, err := fmt.Fprintln(w, e.String()); err != nil {
+			return fmt.Errorf("write an error: %w", err)
+		}
+	}
+	return nil
+}
+
+// targets converts arguments to paths to files which can be validated.
+func targets(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return findFiles("pkgs")
+	}
+	var paths []string
+	for _, arg := range args {
+		stat, err := os.Stat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", arg, err)
+		}
+		if !stat.IsDir() {
+			if !Supported(arg) {
+				return nil, fmt.Errorf("%s isn't registry.yaml, pkg.yaml, or scaffold.yaml", arg)
+			}
+			paths = append(paths, arg)
+			continue
+		}
+		files, err := findFiles(arg)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, files...)
+	}
+	return paths, nil
+}
+
+func findFiles(dir string) ([]string, error) {
+	var paths []string
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && Supported(p) {
+			paths = append(paths, p)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("find files in the directory %s: %w", dir, err)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
@@ -0,0 +1,244 @@
+// Package validate validates registry.yaml, pkg.yaml, and scaffold.yaml with JSON Schemas of aqua.
+// JSON Schemas are embedded in the binary, so no network access is needed.
+package validate
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// AquaVersion is the version of aqua whose JSON Schemas are embedded.
+// Update schemas by `cmdx update-schema` when aqua in go.mod is updated.
+const AquaVersion = "v2.62.3"
+
+//go:embed schema/*.json
+var schemaFS embed.FS
+
+// schemaFiles maps file names to JSON Schemas.
+var schemaFiles = map[string]string{ //nolint:gochecknoglobals
+	"registry.yaml": "registry.json",
+	"pkg.yaml":      "aqua-yaml.json",
+	"scaffold.yaml": "aqua-generate-registry.json",
+}
+
+// Supported returns true if the file can be validated.
+func Supported(p string) bool {
+	_, ok := schemaFiles[path.Base(filepath.ToSlash(p))]
+	return ok
+}
+
+// Error is a violation of a JSON Schema.
+type Error struct {
+	File string `json:"file"`
+	// YAMLPath is the path to the invalid value. e.g. $.packages[0].files[0].name
+	YAMLPath string `json:"yaml_path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+}
+
+func (e *Error) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.YAMLPath, e.Message)
+}
+
+// Validator validates files with embedded JSON Schemas.
+type Validator struct {
+	schemas map[string]*jsonschema.Schema
+	printer *message.Printer
+}
+
+// New compiles embedded JSON Schemas.
+func New() (*Validator, error) {
+	compiler := jsonschema.NewCompiler()
+	v := &Validator{
+		schemas: make(map[string]*jsonschema.Schema, len(schemaFiles)),
+		printer: message.NewPrinter(language.English),
+	}
+	for _, name := range schemaFiles {
+		b, err := schemaFS.ReadFile("schema/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("read the JSON Schema %s: %w", name, err)
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("parse the JSON Schema %s: %w", name, err)
+		}
+		if name == schemaFiles["pkg.yaml"] {
+			allowNoRegistries(doc)
+		}
+		if err := compiler.AddResource(name, doc); err != nil {
+			return nil, fmt.Errorf("add the JSON Schema %s: %w", name, err)
+		}
+		sch, err := compiler.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf("compile the JSON Schema %s: %w", name, err)
+		}
+		v.schemas[name] = sch
+	}
+	return v, nil
+}
+
+// allowNoRegistries removes registries from required properties of aqua.yaml
+// because pkg.yaml uses the registry configured in the test environment.
+func allowNoRegistries(doc any) {
+	defs, ok := doc.(map[string]any)["$defs"].(map[string]any)
+	if !ok {
+		return
+	}
+	cfg, ok := defs["Config"].(map[string]any)
+	if !ok {
+		return
+	}
+	required, ok := cfg["required"].([]any)
+	if !ok {
+		return
+	}
+	cfg["required"] = slices.DeleteFunc(required, func(a any) bool {
+		return a == "registries"
+	})
+}
+
+// ValidateFile validates the file with the JSON Schema chosen by the file name.
+// It returns violations of the JSON Schema.
+// It returns an error if the file can't be read or parsed.
+func (v *Validator) ValidateFile(p string) ([]*Error, error) {
+	name, ok := schemaFiles[path.Base(filepath.ToSlash(p))]
+	if !ok {
+		return nil, fmt.Errorf("no JSON Schema for %s", p)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", p, err)
+	}
+	return v.validate(filepath.ToSlash(p), v.schemas[name], b)
+}
+
+func (v *Validator) validate(p string, sch *jsonschema.Schema, b []byte) ([]*Error, error) {
+	file, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s as YAML: %w", p, err)
+	}
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s to JSON: %w", p, err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(j))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s as JSON: %w", p, err)
+	}
+	err = sch.Validate(doc)
+	if err == nil {
+		return nil, nil
+	}
+	verr := &jsonschema.ValidationError{}
+	if !errors.As(err, &verr) {
+		return nil, fmt.Errorf("validate %s: %w", p, err)
+	}
+	var ret []*Error
+	for _, leaf := range leaves(verr) {
+		yamlPath := toYAMLPath(doc, leaf.InstanceLocation)
+		line, column := position(file, yamlPath)
+		e := &Error{
+			File:     p,
+			YAMLPath: yamlPath,
+			Line:     line,
+			Column:   column,
+			Message:  leaf.ErrorKind.LocalizedString(v.printer),
+		}
+		if !slices.ContainsFunc(ret, func(a *Error) bool {
+			return a.YAMLPath == e.YAMLPath && a.Message == e.Message
+		}) {
+			ret = append(ret, e)
+		}
+	}
+	slices.SortStableFunc(ret, func(a, b *Error) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return ret, nil
+}
+
+// leaves returns errors without causes, which describe the actual violations.
+func leaves(e *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(e.Causes) == 0 {
+		return []*jsonschema.ValidationError{e}
+	}
+	var ret []*jsonschema.ValidationError
+	for _, cause := range e.Causes {
+		ret = append(ret, leaves(cause)...)
+	}
+	return ret
+}
+
+var simpleKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// toYAMLPath converts a JSON Pointer in the document to a YAML path.
+func toYAMLPath(doc any, tokens []string) string {
+	p := "$"
+	cur := doc
+	for _, token := range tokens {
+		switch c := cur.(type) {
+		case []any:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return p
+			}
+			p += fmt.Sprintf("[%d]", idx)
+			cur = c[idx]
+		case map[string]any:
+			if simpleKey.MatchString(token) {
+				p += "." + token
+			} else {
+				p += ".'" + token + "'"
+			}
+			cur = c[token]
+		default:
+			return p
+		}
+	}
+	return p
+}
+
+// position returns the line and the column of the node at the YAML path.
+// It returns 1, 1 if the node isn't found.
+func position(file *ast.File, yamlPath string) (int, int) {
+	p, err := yaml.PathString(yamlPath)
+	if err != nil {
+		return 1, 1
+	}
+	node, err := p.FilterFile(file)
+	if err != nil || node == nil {
+		return 1, 1
+	}
+	// Point to the first key of the mapping rather than the first value.
+	switch m := node.(type) {
+	case *ast.MappingNode:
+		if len(m.Values) > 0 {
+			node = m.Values[0].Key
+		}
+	case *ast.MappingValueNode:
+		node = m.Key
+	}
+	tk := node.GetToken()
+	if tk == nil || tk.Position == nil {
+		return 1, 1
+	}
+	return tk.Position.Line, tk.Position.Column
+}
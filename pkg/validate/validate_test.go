@@ -0,0 +1,168 @@
+package validate_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aquaproj/registry-tool/pkg/testutil"
+	"github.com/aquaproj/registry-tool/pkg/validate"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidator_ValidateFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	v, err := validate.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []struct {
+		name    string
+		file    string
+		content string
+		exp     []*validate.Error
+	}{
+		{
+			name: "valid registry.yaml",
+			file: "registry.yaml",
+			content: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    asset: gh_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz
+    files:
+      - name: gh
+`,
+		},
+		{
+			name: "invalid registry.yaml",
+			file: "registry.yaml",
+			content: `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    files:
+      - name: gh
+        foo: bar
+    supported_envs: linux
+    version_overrides:
+      - version_constraint: 1
+`,
+			exp: []*validate.Error{
+				{
+					YAMLPath: "$.packages[0].files[0]",
+					Line:     6,
+					Column:   9,
+					Message:  "additional properties 'foo' not allowed",
+				},
+				{
+					YAMLPath: "$.packages[0].supported_envs",
+					Line:     8,
+					Column:   21,
+					Message:  "got string, want array",
+				},
+				{
+					YAMLPath: "$.packages[0].version_overrides[0].version_constraint",
+					Line:     10,
+					Column:   29,
+					Message:  "got number, want string",
+				},
+			},
+		},
+		{
+			name: "pkg.yaml without registries",
+			file: "pkg.yaml",
+			content: `packages:
+  - name: cli/cli@v2.0.0
+  - name: cli/cli
+    version: v1.0.0
+`,
+		},
+		{
+			name: "invalid pkg.yaml",
+			file: "pkg.yaml",
+			content: `packages:
+  - name: cli/cli@v2.0.0
+    unknown: true
+`,
+			exp: []*validate.Error{
+				{
+					YAMLPath: "$.packages[0]",
+					Line:     2,
+					Column:   5,
+					Message:  "additional properties 'unknown' not allowed",
+				},
+			},
+		},
+		{
+			name:    "invalid scaffold.yaml",
+			file:    "scaffold.yaml",
+			content: "version_filter: true\n",
+			exp: []*validate.Error{
+				{
+					YAMLPath: "$",
+					Line:     1,
+					Column:   1,
+					Message:  "missing property 'name'",
+				},
+				{
+					YAMLPath: "$.version_filter",
+					Line:     1,
+					Column:   17,
+					Message:  "got boolean, want string",
+				},
+			},
+		},
+	}
+	for i, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+			p := filepath.Join(dir, strings.Repeat("x", i+1), d.file)
+			testutil.WriteFile(t, p, d.content)
+			errs, err := v.ValidateFile(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range d.exp {
+				e.File = filepath.ToSlash(p)
+			}
+			if diff := cmp.Diff(d.exp, errs); diff != "" {
+				t.Fatalf("errors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.WriteFile(t, "pkgs/cli/cli/registry.yaml", `packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+`)
+	testutil.WriteFile(t, "pkgs/cli/cli/pkg.yaml", `packages:
+  - name: cli/cli@v2.0.0
+`)
+	testutil.WriteFile(t, "pkgs/cli/cli/README.md", "# cli/cli\n")
+	buf := &strings.Builder{}
+	if err := validate.Run(&validate.Config{
+		Format: validate.FormatText,
+		Stdout: buf,
+	}); err != nil {
+		t.Fatalf("files should be valid: %v\n%s", err, buf.String())
+	}
+
+	testutil.WriteFile(t, "pkgs/cli/cli/scaffold.yaml", "name: 1\n")
+	if err := validate.Run(&validate.Config{
+		Args:   []string{"pkgs/cli"},
+		Format: validate.FormatText,
+		Stdout: buf,
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	exp := "pkgs/cli/cli/scaffold.yaml:1:7: $.name: got number, want string\n"
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("output (-want +got):\n%s", diff)
+	}
+}